@@ -1 +1,4 @@
-terraform import haproxy-pf_backend parent-frontend-name/bind-resource-name
\ No newline at end of file
+# frontend/<parent frontend name>/<bind name>, names containing "/" escaped as %2F
+terraform import haproxy-pf_bind.bind-resource-name frontend/parent-frontend-name/bind-name
+# the legacy <parent frontend name>/<bind name> form is still accepted when no name contains "/"
+terraform import haproxy-pf_bind.bind-resource-name parent-frontend-name/bind-name
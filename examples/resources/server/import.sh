@@ -1 +1,4 @@
-terraform import haproxy-pf_backend parent-backend-name/server-resource-name
\ No newline at end of file
+# backend/<parent backend name>/<server name>, names containing "/" escaped as %2F
+terraform import haproxy-pf_server.server-resource-name backend/parent-backend-name/server-name
+# the legacy <parent backend name>/<server name> form is still accepted when no name contains "/"
+terraform import haproxy-pf_server.server-resource-name parent-backend-name/server-name
@@ -1 +1,4 @@
-terraform import haproxy-pf_server_template.server-resource-name parent-backend-name/prefix-name
\ No newline at end of file
+# backend/<parent backend name>/<prefix>, names containing "/" escaped as %2F
+terraform import haproxy-pf_server_template.server-resource-name backend/parent-backend-name/prefix-name
+# the legacy <parent backend name>/<prefix> form is still accepted when no name contains "/"
+terraform import haproxy-pf_server_template.server-resource-name parent-backend-name/prefix-name
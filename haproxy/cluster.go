@@ -0,0 +1,24 @@
+package haproxy
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// withDriftWarnings returns a context recording the cluster nodes diverging
+// on the objects read with it, and a function adding them as warnings to diags.
+func withDriftWarnings(ctx context.Context) (context.Context, func(diags *diag.Diagnostics)) {
+	ctx, report := middleware.WithDriftReport(ctx)
+	return ctx, func(diags *diag.Diagnostics) {
+		for _, drift := range report.Drifts() {
+			diags.AddWarning(
+				"Haproxy node drift",
+				fmt.Sprintf("Node %s diverges on %s: %s.", drift.Node, drift.Object, drift.Detail),
+			)
+		}
+	}
+}
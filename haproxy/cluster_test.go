@@ -0,0 +1,78 @@
+package haproxy
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccClusterBackendResource(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	clusterConfig := testProviderConfig(nodes)
+
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: clusterConfig + fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "roundrobin"
+					mode = "http"
+				}
+				`, backendName, backendName),
+				Check: func(*terraform.State) error {
+					for i, node := range nodes {
+						if _, ok := node.Object("backends", "", backendName); !ok {
+							return fmt.Errorf("backend %s missing on node %d", backendName, i)
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccClusterNodeDown(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+	// the last node refuses connections
+	nodes[2].Close()
+	clusterConfig := func(quorum int) string {
+		return testProviderConfig(nodes, fmt.Sprintf("quorum = %d", quorum))
+	}
+
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	backendConfig := fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "roundrobin"
+					mode = "http"
+				}
+				`, backendName, backendName)
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      clusterConfig(3) + backendConfig,
+				ExpectError: regexp.MustCompile("quorum not reached"),
+			},
+			{
+				Config: clusterConfig(2) + backendConfig,
+				Check: func(*terraform.State) error {
+					for i, node := range nodes[:2] {
+						if _, ok := node.Object("backends", "", backendName); !ok {
+							return fmt.Errorf("backend %s missing on node %d", backendName, i)
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
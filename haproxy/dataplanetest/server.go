@@ -0,0 +1,830 @@
+// Package dataplanetest provides an in-process fake of the HAProxy Data Plane
+// API, for tests that cannot reach a real HAProxy.
+//
+// The fake keeps a versioned configuration made of backends, frontends,
+// resolvers, servers, server templates and binds, and implements the v2 and
+// v3 URL layouts, transactions and the 404/409 semantics of the real API.
+// Raw configurations posted with only_validate are checked for references to
+// missing sections, such as a default_backend that does not exist.
+package dataplanetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	Username = "admin"
+	Password = "adminpwd"
+
+	DataPlaneVersion = "2.6.1"
+	HAProxyVersion   = "2.6.5"
+)
+
+type object = map[string]interface{}
+
+// childKind describes a configuration object nested in a parent section.
+type childKind struct {
+	parentSection string
+	parentType    string
+	keyField      string
+}
+
+var sections = map[string]bool{
+	"backends":  true,
+	"frontends": true,
+	"resolvers": true,
+}
+
+// reference is an attribute naming another section, rendered in the raw
+// configuration and checked when validating it.
+type reference struct {
+	field   string
+	section string
+}
+
+var references = map[string]reference{
+	"frontends":        {field: "default_backend", section: "backends"},
+	"server_templates": {field: "resolvers", section: "resolvers"},
+}
+
+var childKinds = map[string]childKind{
+	"servers":          {parentSection: "backends", parentType: "backend", keyField: "name"},
+	"server_templates": {parentSection: "backends", parentType: "backend", keyField: "prefix"},
+	"binds":            {parentSection: "frontends", parentType: "frontend", keyField: "name"},
+}
+
+// Server is a fake Data Plane API listening on a local port.
+type Server struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	apiVersions     map[string]bool
+	version         int
+	config          *configuration
+	transactions    map[string]*transaction
+	nextTransaction int
+	requests        []string
+	reloads         map[string]*reload
+	reloadFailure   string
+	haproxyVersion  string
+	// run before the next transaction is created, see BeforeNextTransaction
+	beforeTransaction func()
+}
+
+type configuration struct {
+	// section -> name -> object
+	sections map[string]map[string]object
+	// child kind -> parent name -> name -> object
+	children map[string]map[string]map[string]object
+}
+
+// reload is reported in progress on its first status request, then
+// succeeded or failed.
+type reload struct {
+	id       string
+	status   string
+	response string
+	// status and response once done
+	result  string
+	failure string
+}
+
+type transaction struct {
+	id      string
+	version int
+	status  string
+	config  *configuration
+}
+
+// NewServer starts a fake Data Plane API serving the given layouts, "v2"
+// and "v3" when none is given. It must be closed by the caller.
+func NewServer(apiVersions ...string) *Server {
+	if len(apiVersions) == 0 {
+		apiVersions = []string{"v2", "v3"}
+	}
+	s := &Server{
+		apiVersions:  map[string]bool{},
+		version:      1,
+		config:       newConfiguration(),
+		transactions: map[string]*transaction{},
+		reloads:      map[string]*reload{},
+
+		haproxyVersion: HAProxyVersion,
+	}
+	for _, apiVersion := range apiVersions {
+		s.apiVersions[apiVersion] = true
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Host returns the host:port the fake listens on, as expected by the provider.
+func (s *Server) Host() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// Version returns the current configuration version.
+func (s *Server) Version() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// OpenTransactions returns the number of transactions neither committed nor deleted.
+func (s *Server) OpenTransactions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, tx := range s.transactions {
+		if tx.status == "in_progress" {
+			count++
+		}
+	}
+	return count
+}
+
+// FailReloads makes the reloads of the next commits fail with response,
+// or succeed again when response is empty.
+func (s *Server) FailReloads(response string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reloadFailure = response
+}
+
+// SetHAProxyVersion sets the version of the HAProxy process reported by the
+// runtime information, which is denied when version is empty as for a Data
+// Plane API user without runtime access.
+func (s *Server) SetHAProxyVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.haproxyVersion = version
+}
+
+// BeforeNextTransaction makes fn run when the next transaction is requested,
+// before it is created. It allows changing the configuration out of band
+// between the refresh and the apply of a plan.
+func (s *Server) BeforeNextTransaction(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.beforeTransaction = fn
+}
+
+// Reloads returns the ids of the reloads scheduled or forced so far.
+func (s *Server) Reloads() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.reloads))
+	for i := 1; i <= len(s.reloads); i++ {
+		ids = append(ids, fmt.Sprintf("reload-%d", i))
+	}
+	return ids
+}
+
+// Requests returns the "METHOD /path" of every request received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Object returns a copy of a committed section object, e.g. Object("backends", "", "be_web"),
+// or of a child object, e.g. Object("servers", "be_web", "srv1").
+func (s *Server) Object(kind string, parent string, name string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objects := s.config.collection(kind, parent)
+	if objects == nil || objects[name] == nil {
+		return nil, false
+	}
+	return copyObject(objects[name]), true
+}
+
+// PutObject creates or replaces a committed object out of band, bumping the configuration version.
+func (s *Server) PutObject(kind string, parent string, obj map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	config := s.config.clone()
+	config.ensureCollection(kind, parent)[keyOf(kind, obj)] = copyObject(obj)
+	s.config = config
+	s.version++
+}
+
+// DeleteObject removes a committed object out of band, bumping the configuration version.
+func (s *Server) DeleteObject(kind string, parent string, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	config := s.config.clone()
+	config.delete(kind, parent, name)
+	s.config = config
+	s.version++
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if username, password, ok := r.BasicAuth(); !ok || username != Username || password != Password {
+		writeError(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+
+	segments, err := splitPath(r.URL.EscapedPath())
+	if err != nil || len(segments) == 0 || !s.apiVersions[segments[0]] {
+		writeError(w, http.StatusNotFound, "path "+r.URL.Path+" not found")
+		return
+	}
+	req := &request{
+		Request:    r,
+		apiVersion: segments[0],
+		segments:   segments[1:],
+	}
+
+	switch {
+	case req.match("info"):
+		s.handleInfo(w, req)
+	case req.match("services", "haproxy", "runtime", "info"):
+		s.handleRuntimeInfo(w, req)
+	case req.match("services", "haproxy", "stats", "native"):
+		writeJSON(w, http.StatusOK, []interface{}{})
+	case len(req.segments) == 4 && req.hasPrefix("services", "haproxy", "reloads"):
+		s.handleReload(w, req, req.segments[3])
+	case req.hasPrefix("services", "haproxy", "transactions"):
+		s.handleTransactions(w, req, req.segments[3:])
+	case req.match("services", "haproxy", "configuration", "raw"):
+		s.handleRaw(w, req)
+	case req.match("services", "haproxy", "configuration", "version"):
+		writeJSON(w, http.StatusOK, s.version)
+	case req.hasPrefix("services", "haproxy", "configuration"):
+		s.handleConfiguration(w, req, req.segments[3:])
+	default:
+		writeError(w, http.StatusNotFound, "path "+r.URL.Path+" not found")
+	}
+}
+
+type request struct {
+	*http.Request
+	apiVersion string
+	segments   []string
+}
+
+func (r *request) hasPrefix(prefix ...string) bool {
+	if len(r.segments) < len(prefix) {
+		return false
+	}
+	for i, segment := range prefix {
+		if r.segments[i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *request) match(segments ...string) bool {
+	return len(r.segments) == len(segments) && r.hasPrefix(segments...)
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, r *request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	version := DataPlaneVersion
+	if r.apiVersion == "v3" {
+		version = "3.0.1"
+	}
+	writeJSON(w, http.StatusOK, object{
+		"api":    object{"version": "v" + version + " 0000000", "build_date": "2022-10-13T12:00:00Z"},
+		"system": object{"hostname": "dataplanetest"},
+	})
+}
+
+func (s *Server) handleRuntimeInfo(w http.ResponseWriter, r *request) {
+	if s.haproxyVersion == "" {
+		writeError(w, http.StatusForbidden, "runtime API access denied")
+		return
+	}
+	info := object{"info": object{"version": s.haproxyVersion, "release_date": "2022/10/08"}}
+	if r.apiVersion == "v2" {
+		writeJSON(w, http.StatusOK, []interface{}{info})
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (s *Server) handleRaw(w http.ResponseWriter, r *request) {
+	if r.Method == http.MethodPost {
+		s.handleValidate(w, r)
+		return
+	}
+
+	config := s.config
+	if id := r.URL.Query().Get("transaction_id"); id != "" {
+		tx, ok := s.transactions[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "transaction "+id+" not found")
+			return
+		}
+		config = tx.config
+	}
+
+	if r.apiVersion == "v3" {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Configuration-Version", strconv.Itoa(s.version))
+		_, _ = io.WriteString(w, config.render())
+		return
+	}
+	writeJSON(w, http.StatusOK, object{"_version": s.version, "data": config.render()})
+}
+
+// handleValidate checks a raw configuration posted with only_validate, the
+// fake reporting references to missing sections like HAProxy does.
+func (s *Server) handleValidate(w http.ResponseWriter, r *request) {
+	if r.URL.Query().Get("only_validate") != "true" {
+		writeError(w, http.StatusNotImplemented, "only the validation of a raw configuration is supported")
+		return
+	}
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validateRaw(string(raw)); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handleTransactions(w http.ResponseWriter, r *request, segments []string) {
+	if len(segments) == 0 {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if fn := s.beforeTransaction; fn != nil {
+			// fn uses the exported methods, which take the lock
+			s.beforeTransaction = nil
+			s.mu.Unlock()
+			fn()
+			s.mu.Lock()
+		}
+		version, err := strconv.Atoi(r.URL.Query().Get("version"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "version is required")
+			return
+		}
+		if version != s.version {
+			writeError(w, http.StatusConflict, fmt.Sprintf("version mismatch, have %d, given %d", s.version, version))
+			return
+		}
+		s.nextTransaction++
+		tx := &transaction{
+			id:      fmt.Sprintf("tx-%d", s.nextTransaction),
+			version: s.version,
+			status:  "in_progress",
+			config:  s.config.clone(),
+		}
+		s.transactions[tx.id] = tx
+		writeJSON(w, http.StatusCreated, tx.json())
+		return
+	}
+
+	if len(segments) != 1 {
+		writeError(w, http.StatusNotFound, "path "+r.URL.Path+" not found")
+		return
+	}
+	tx, ok := s.transactions[segments[0]]
+	if !ok || tx.status != "in_progress" {
+		writeError(w, http.StatusNotFound, "transaction "+segments[0]+" not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, tx.json())
+	case http.MethodPut:
+		if tx.version != s.version {
+			tx.status = "failed"
+			writeError(w, http.StatusConflict, fmt.Sprintf("transaction %s is outdated, version %d is committed", tx.id, s.version))
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("force_reload") == "true" && s.reloadFailure != "" {
+			// a failed forced reload rolls the configuration back
+			tx.status = "failed"
+			writeError(w, http.StatusBadRequest, s.reloadFailure)
+			return
+		}
+		s.config = tx.config
+		s.version++
+		tx.status = "success"
+		tx.version = s.version
+		switch {
+		case query.Get("skip_reload") == "true":
+			writeJSON(w, http.StatusOK, tx.json())
+		case query.Get("force_reload") == "true":
+			id := s.nextReloadId()
+			s.reloads[id] = &reload{id: id, status: "succeeded", result: "succeeded"}
+			writeJSON(w, http.StatusOK, tx.json())
+		default:
+			s.scheduleReload(w)
+			writeJSON(w, http.StatusAccepted, tx.json())
+		}
+	case http.MethodDelete:
+		delete(s.transactions, tx.id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleConfiguration serves sections (backends/{name}), v2 children
+// (servers/{name}?parent_name=) and v3 children (backends/{parent}/servers/{name}).
+func (s *Server) handleConfiguration(w http.ResponseWriter, r *request, segments []string) {
+	if len(segments) == 0 {
+		writeError(w, http.StatusNotFound, "path "+r.URL.Path+" not found")
+		return
+	}
+
+	kind := segments[0]
+	parent := ""
+	rest := segments[1:]
+
+	if kindInfo, ok := childKinds[kind]; ok && r.apiVersion == "v2" {
+		query := r.URL.Query()
+		parent = query.Get("parent_name")
+		if parent == "" {
+			parent = query.Get(kindInfo.parentType)
+		}
+		if parent == "" {
+			writeError(w, http.StatusBadRequest, "parent_name is required")
+			return
+		}
+	} else if !sections[kind] {
+		writeError(w, http.StatusNotFound, "path "+r.URL.Path+" not found")
+		return
+	} else if r.apiVersion == "v3" && len(segments) >= 3 {
+		parent = segments[1]
+		kind = segments[2]
+		rest = segments[3:]
+		if kindInfo, ok := childKinds[kind]; !ok || kindInfo.parentSection != segments[0] {
+			writeError(w, http.StatusNotFound, "path "+r.URL.Path+" not found")
+			return
+		}
+	}
+
+	if len(rest) > 1 {
+		writeError(w, http.StatusNotFound, "path "+r.URL.Path+" not found")
+		return
+	}
+	name := ""
+	if len(rest) == 1 {
+		name = rest[0]
+	}
+
+	s.handleObject(w, r, kind, parent, name)
+}
+
+func (s *Server) handleObject(w http.ResponseWriter, r *request, kind string, parent string, name string) {
+	config, tx, ok := s.target(w, r)
+	if !ok {
+		return
+	}
+
+	if parent != "" {
+		parentSection := childKinds[kind].parentSection
+		if config.sections[parentSection][parent] == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s not found", strings.TrimSuffix(parentSection, "s"), parent))
+			return
+		}
+	}
+	objects := config.collection(kind, parent)
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		list := []interface{}{}
+		for _, key := range sortedKeys(objects) {
+			list = append(list, objects[key])
+		}
+		s.writeData(w, r, list)
+	case r.Method == http.MethodGet:
+		if objects[name] == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("object %s not found", name))
+			return
+		}
+		s.writeData(w, r, objects[name])
+	case r.Method == http.MethodPost && name == "":
+		obj, ok := decodeObject(w, r)
+		if !ok {
+			return
+		}
+		key := keyOf(kind, obj)
+		if key == "" {
+			writeError(w, http.StatusUnprocessableEntity, "name is required")
+			return
+		}
+		if objects[key] != nil {
+			writeError(w, http.StatusConflict, fmt.Sprintf("object %s already exists", key))
+			return
+		}
+		config.ensureCollection(kind, parent)[key] = obj
+		s.finishChange(w, tx, config, http.StatusCreated, obj)
+	case r.Method == http.MethodPut && name != "":
+		obj, ok := decodeObject(w, r)
+		if !ok {
+			return
+		}
+		if objects[name] == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("object %s not found", name))
+			return
+		}
+		if key := keyOf(kind, obj); key != name {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("name %q does not match %q", key, name))
+			return
+		}
+		objects[name] = obj
+		s.finishChange(w, tx, config, http.StatusOK, obj)
+	case r.Method == http.MethodDelete && name != "":
+		if objects[name] == nil {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("object %s not found", name))
+			return
+		}
+		config.delete(kind, parent, name)
+		s.finishChange(w, tx, config, http.StatusNoContent, nil)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// target returns the configuration a request applies to: the one of its
+// transaction, a copy of the committed one for versioned writes, or the
+// committed one for reads.
+func (s *Server) target(w http.ResponseWriter, r *request) (*configuration, *transaction, bool) {
+	query := r.URL.Query()
+	if id := query.Get("transaction_id"); id != "" {
+		tx, ok := s.transactions[id]
+		if !ok || tx.status != "in_progress" {
+			writeError(w, http.StatusNotFound, "transaction "+id+" not found")
+			return nil, nil, false
+		}
+		return tx.config, tx, true
+	}
+
+	if r.Method == http.MethodGet {
+		return s.config, nil, true
+	}
+
+	version, err := strconv.Atoi(query.Get("version"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "version or transaction_id is required")
+		return nil, nil, false
+	}
+	if version != s.version {
+		writeError(w, http.StatusConflict, fmt.Sprintf("version mismatch, have %d, given %d", s.version, version))
+		return nil, nil, false
+	}
+	return s.config.clone(), nil, true
+}
+
+// finishChange commits versioned writes immediately, writes inside a
+// transaction are kept until the transaction is committed.
+func (s *Server) finishChange(w http.ResponseWriter, tx *transaction, config *configuration, status int, obj object) {
+	if tx == nil {
+		s.config = config
+		s.version++
+		s.scheduleReload(w)
+		status = http.StatusAccepted
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	writeJSON(w, status, obj)
+}
+
+// scheduleReload records a reload of the committed configuration and
+// returns its id in the Reload-ID header.
+func (s *Server) scheduleReload(w http.ResponseWriter) {
+	id := s.nextReloadId()
+	res := &reload{id: id, status: "in_progress", result: "succeeded"}
+	if s.reloadFailure != "" {
+		res.result, res.failure = "failed", s.reloadFailure
+	}
+	s.reloads[id] = res
+	w.Header().Set("Reload-ID", id)
+}
+
+func (s *Server) nextReloadId() string {
+	return fmt.Sprintf("reload-%d", len(s.reloads)+1)
+}
+
+func (s *Server) handleReload(w http.ResponseWriter, r *request, id string) {
+	reload, ok := s.reloads[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "reload "+id+" not found")
+		return
+	}
+	res := object{"id": reload.id, "status": reload.status, "response": reload.response}
+	reload.status, reload.response = reload.result, reload.failure
+	writeJSON(w, http.StatusOK, res)
+}
+
+// writeData writes a GET response, wrapped in {"_version", "data"} for v2
+// and with the version in the Configuration-Version header for v3.
+func (s *Server) writeData(w http.ResponseWriter, r *request, data interface{}) {
+	w.Header().Set("Configuration-Version", strconv.Itoa(s.version))
+	if r.apiVersion == "v2" {
+		writeJSON(w, http.StatusOK, object{"_version": s.version, "data": data})
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (tx *transaction) json() object {
+	return object{"_version": tx.version, "id": tx.id, "status": tx.status}
+}
+
+func newConfiguration() *configuration {
+	config := &configuration{
+		sections: map[string]map[string]object{},
+		children: map[string]map[string]map[string]object{},
+	}
+	for section := range sections {
+		config.sections[section] = map[string]object{}
+	}
+	for kind := range childKinds {
+		config.children[kind] = map[string]map[string]object{}
+	}
+	return config
+}
+
+// clone copies the structure of the configuration, objects are replaced
+// rather than modified so they can be shared.
+func (c *configuration) clone() *configuration {
+	res := newConfiguration()
+	for section, objects := range c.sections {
+		for name, obj := range objects {
+			res.sections[section][name] = obj
+		}
+	}
+	for kind, parents := range c.children {
+		for parent, objects := range parents {
+			res.children[kind][parent] = map[string]object{}
+			for name, obj := range objects {
+				res.children[kind][parent][name] = obj
+			}
+		}
+	}
+	return res
+}
+
+func (c *configuration) collection(kind string, parent string) map[string]object {
+	if _, ok := childKinds[kind]; ok {
+		return c.children[kind][parent]
+	}
+	return c.sections[kind]
+}
+
+func (c *configuration) ensureCollection(kind string, parent string) map[string]object {
+	if _, ok := childKinds[kind]; ok && c.children[kind][parent] == nil {
+		c.children[kind][parent] = map[string]object{}
+	}
+	return c.collection(kind, parent)
+}
+
+// delete removes an object, and the children of a deleted section.
+func (c *configuration) delete(kind string, parent string, name string) {
+	if objects := c.collection(kind, parent); objects != nil {
+		delete(objects, name)
+	}
+	for childKind, info := range childKinds {
+		if info.parentSection == kind {
+			delete(c.children[childKind], name)
+		}
+	}
+}
+
+// render returns a rough haproxy.cfg view of the configuration.
+func (c *configuration) render() string {
+	var b strings.Builder
+	for _, section := range sortedKeys(sections) {
+		for _, name := range sortedKeys(c.sections[section]) {
+			fmt.Fprintf(&b, "%s %s\n", strings.TrimSuffix(section, "s"), name)
+			if ref, ok := references[section]; ok {
+				if value, _ := c.sections[section][name][ref.field].(string); value != "" {
+					fmt.Fprintf(&b, "  %s %s\n", ref.field, value)
+				}
+			}
+			for _, kind := range sortedKeys(childKinds) {
+				if childKinds[kind].parentSection != section {
+					continue
+				}
+				for _, child := range sortedKeys(c.children[kind][name]) {
+					fmt.Fprintf(&b, "  %s %s", strings.TrimSuffix(kind, "s"), child)
+					if ref, ok := references[kind]; ok {
+						if value, _ := c.children[kind][name][child][ref.field].(string); value != "" {
+							fmt.Fprintf(&b, " %s %s", ref.field, value)
+						}
+					}
+					b.WriteString("\n")
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// validateRaw returns an error for the references of a rendered
+// configuration to sections it does not declare.
+func validateRaw(raw string) error {
+	declared := map[string]bool{}
+	lines := strings.Split(raw, "\n")
+	for _, line := range lines {
+		if fields := strings.Fields(line); len(fields) == 2 && !strings.HasPrefix(line, " ") {
+			declared[fields[0]+"s/"+fields[1]] = true
+		}
+	}
+	for n, line := range lines {
+		fields := strings.Fields(line)
+		if !strings.HasPrefix(line, " ") {
+			continue
+		}
+		for _, ref := range references {
+			for i := 0; i+1 < len(fields); i++ {
+				if fields[i] == ref.field && !declared[ref.section+"/"+fields[i+1]] {
+					return fmt.Errorf("[ALERT] config : line %d: %s '%s' not found", n+1, ref.field, fields[i+1])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func keyOf(kind string, obj object) string {
+	keyField := "name"
+	if info, ok := childKinds[kind]; ok {
+		keyField = info.keyField
+	}
+	key, _ := obj[keyField].(string)
+	return key
+}
+
+func decodeObject(w http.ResponseWriter, r *request) (object, bool) {
+	var obj object
+	if err := json.NewDecoder(r.Body).Decode(&obj); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return nil, false
+	}
+	return obj, true
+}
+
+func copyObject(obj object) object {
+	res := object{}
+	for key, value := range obj {
+		res[key] = value
+	}
+	return res
+}
+
+func splitPath(escapedPath string) ([]string, error) {
+	segments := []string{}
+	for _, segment := range strings.Split(strings.Trim(escapedPath, "/"), "/") {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, unescaped)
+	}
+	return segments, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, object{"code": status, "message": message})
+}
@@ -0,0 +1,195 @@
+package dataplanetest_test
+
+import (
+	"context"
+	"testing"
+
+	"terraform-provider-haproxy-pf/haproxy/dataplanetest"
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+	"terraform-provider-haproxy-pf/haproxy/models"
+)
+
+func newClient(t *testing.T, server *dataplanetest.Server, apiVersion string) *middleware.Client {
+	t.Helper()
+	client := middleware.NewClient(dataplanetest.Username, dataplanetest.Password, server.Host(), true)
+	if err := client.SetAPIVersion(apiVersion); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestServerLifecycle(t *testing.T) {
+	for _, apiVersion := range []string{middleware.APIVersion2, middleware.APIVersion3} {
+		t.Run(apiVersion, func(t *testing.T) {
+			server := dataplanetest.NewServer()
+			defer server.Close()
+			client := newClient(t, server, apiVersion)
+			ctx := context.Background()
+
+			err := client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
+				if _, err := client.CreateBackend(ctx, transactionId, models.Backend{Name: "be_web", Mode: "http"}); err != nil {
+					return err
+				}
+				_, err := client.CreateServer(ctx, transactionId, models.Server{Name: "srv1", Address: "10.0.0.1", Port: 80}, "be_web")
+				return err
+			})
+			if err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			if got := server.Version(); got != 2 {
+				t.Errorf("version = %d, want 2", got)
+			}
+			if got := server.OpenTransactions(); got != 0 {
+				t.Errorf("open transactions = %d, want 0", got)
+			}
+
+			srv, err := client.GetServer(ctx, "srv1", "be_web")
+			if err != nil {
+				t.Fatalf("get server: %v", err)
+			}
+			if srv.Address != "10.0.0.1" || srv.Port != 80 {
+				t.Errorf("unexpected server %+v", srv)
+			}
+
+			err = client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
+				return client.DeleteBackend(ctx, transactionId, "be_web")
+			})
+			if err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if _, err := client.GetBackend(ctx, "be_web"); !middleware.IsNotFound(err) {
+				t.Errorf("backend still readable: %v", err)
+			}
+			if _, ok := server.Object("servers", "be_web", "srv1"); ok {
+				t.Error("server of deleted backend still exists")
+			}
+		})
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	for _, apiVersion := range []string{middleware.APIVersion2, middleware.APIVersion3} {
+		t.Run(apiVersion, func(t *testing.T) {
+			server := dataplanetest.NewServer()
+			defer server.Close()
+			client := newClient(t, server, apiVersion)
+			ctx := context.Background()
+
+			server.PutObject("backends", "", map[string]interface{}{"name": "be_web"})
+
+			tests := []struct {
+				name  string
+				fn    func(ctx context.Context, transactionId string) error
+				check func(error) bool
+			}{
+				{
+					name: "create existing object",
+					fn: func(ctx context.Context, transactionId string) error {
+						_, err := client.CreateBackend(ctx, transactionId, models.Backend{Name: "be_web"})
+						return err
+					},
+					check: middleware.IsConflict,
+				},
+				{
+					name: "update missing object",
+					fn: func(ctx context.Context, transactionId string) error {
+						_, err := client.UpdateBackend(ctx, transactionId, "be_missing", models.Backend{Name: "be_missing"})
+						return err
+					},
+					check: middleware.IsNotFound,
+				},
+				{
+					name: "delete missing object",
+					fn: func(ctx context.Context, transactionId string) error {
+						return client.DeleteServer(ctx, transactionId, "srv1", "be_web")
+					},
+					check: middleware.IsNotFound,
+				},
+				{
+					name: "create child of missing parent",
+					fn: func(ctx context.Context, transactionId string) error {
+						_, err := client.CreateBind(ctx, transactionId, models.Bind{Name: "http"}, "fe_missing")
+						return err
+					},
+					check: middleware.IsNotFound,
+				},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					err := client.WithTransaction(ctx, tt.fn)
+					if !tt.check(err) {
+						t.Errorf("unexpected error: %v", err)
+					}
+					if got := server.OpenTransactions(); got != 0 {
+						t.Errorf("open transactions = %d, want 0", got)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestServerOutdatedTransaction(t *testing.T) {
+	server := dataplanetest.NewServer()
+	defer server.Close()
+	client := newClient(t, server, middleware.APIVersion2)
+	ctx := context.Background()
+
+	configuration, err := client.GetConfiguration(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transaction, err := client.CreateTransaction(ctx, configuration.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateFrontend(ctx, transaction.Id, models.Frontend{Name: "fe_web"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetFrontend(ctx, "fe_web"); !middleware.IsNotFound(err) {
+		t.Errorf("uncommitted frontend readable: %v", err)
+	}
+
+	server.PutObject("backends", "", map[string]interface{}{"name": "be_other"})
+
+	if _, err := client.CommitTransaction(ctx, transaction.Id); !middleware.IsConflict(err) {
+		t.Errorf("commit of outdated transaction: %v", err)
+	}
+	if _, err := client.CreateTransaction(ctx, configuration.Version); !middleware.IsConflict(err) {
+		t.Errorf("transaction on outdated version: %v", err)
+	}
+}
+
+func TestServerAPIVersions(t *testing.T) {
+	server := dataplanetest.NewServer(middleware.APIVersion2)
+	defer server.Close()
+	client := middleware.NewClient(dataplanetest.Username, dataplanetest.Password, server.Host(), true)
+
+	apiVersion, err := client.DetectAPIVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apiVersion != middleware.APIVersion2 {
+		t.Errorf("detected %s, want %s", apiVersion, middleware.APIVersion2)
+	}
+	if err := client.Negotiate(context.Background()); err != nil {
+		t.Errorf("negotiate: %v", err)
+	}
+	if err := client.DetectHAProxyVersion(context.Background()); err != nil {
+		t.Errorf("detect haproxy version: %v", err)
+	}
+	if got := client.HAProxyVersion(); got != dataplanetest.HAProxyVersion {
+		t.Errorf("haproxy version %q, want %q", got, dataplanetest.HAProxyVersion)
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	server := dataplanetest.NewServer()
+	defer server.Close()
+	client := middleware.NewClient(dataplanetest.Username, "wrong", server.Host(), true)
+
+	if err := client.TestApiCall(context.Background()); !middleware.IsUnauthorized(err) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -78,7 +78,7 @@ func (d *backendsDataSource) Read(ctx context.Context, req datasource.ReadReques
 
 	var state backendsDataSourceModel
 
-	backends, err := d.client.GetBackends()
+	backends, err := d.client.GetBackends(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read Haproxy Backends",
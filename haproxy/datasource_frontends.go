@@ -91,7 +91,7 @@ func (d *frontendsDataSource) Read(ctx context.Context, req datasource.ReadReque
 
 	var state frontendsDataSourceModel
 
-	frontends, err := d.client.GetFrontends()
+	frontends, err := d.client.GetFrontends(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read Haproxy Frontends",
@@ -70,7 +70,7 @@ func (d *resolversDataSource) Read(ctx context.Context, req datasource.ReadReque
 
 	var state resolversDataSourceModel
 
-	resolvers, err := d.client.GetResolvers()
+	resolvers, err := d.client.GetResolvers(ctx)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to Read Haproxy Resolvers",
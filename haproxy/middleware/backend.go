@@ -1,90 +1,37 @@
 package middleware
 
 import (
-	"bytes"
-	"encoding/json"
-	"net/http"
+	"context"
 	"terraform-provider-haproxy-pf/haproxy/models"
 )
 
+func (c *Client) backends() objectClient[models.Backend] {
+	return sectionObjects[models.Backend](c, "backends")
+}
+
 // return all backends
-func (c *Client) GetBackends() (*models.GetBackends, error) {
-	url := c.base_url + "/services/haproxy/configuration/backends/"
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) GetBackends(ctx context.Context) (*models.GetBackends, error) {
+	data, version, err := c.backends().list(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 
-	res := models.GetBackends{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+	return &models.GetBackends{Version: version, Data: data}, nil
 }
 
 // return single backend
-func (c *Client) GetBackend(backendName string) (*models.Backend, error) {
-	url := c.base_url + "/services/haproxy/configuration/backends/" + backendName
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	res := models.GetBackend{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res.Data, nil
+func (c *Client) GetBackend(ctx context.Context, backendName string) (*models.Backend, error) {
+	return c.backends().get(ctx, "", backendName)
 }
 
-func (c *Client) CreateBackend(transactionId string, backend models.Backend) (*models.Backend, error) {
-	url := c.base_url + "/services/haproxy/configuration/backends?transaction_id=" + transactionId
-	bodyStr, _ := json.Marshal(backend)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyStr))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	res := models.Backend{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+func (c *Client) CreateBackend(ctx context.Context, transactionId string, backend models.Backend) (*models.Backend, error) {
+	return c.backends().create(ctx, transactionId, "", backend)
 }
 
-func (c *Client) UpdateBackend(transactionId string, backendName string, backend models.Backend) (*models.Backend, error) {
-	url := c.base_url + "/services/haproxy/configuration/backends/" + backendName + "?transaction_id=" + transactionId
-	bodyStr, _ := json.Marshal(backend)
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(bodyStr))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	res := models.Backend{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+func (c *Client) UpdateBackend(ctx context.Context, transactionId string, backendName string, backend models.Backend) (*models.Backend, error) {
+	return c.backends().update(ctx, transactionId, "", backendName, backend)
 }
 
-func (c *Client) DeleteBackend(transactionId string, backendName string) error {
-	url := c.base_url + "/services/haproxy/configuration/backends/" + backendName + "?transaction_id=" + transactionId
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return err
-	}
-
-	if err := c.sendRequest(req, nil); err != nil {
-		return err
-	}
-
-	return nil
+func (c *Client) DeleteBackend(ctx context.Context, transactionId string, backendName string) error {
+	return c.backends().delete(ctx, transactionId, "", backendName)
 }
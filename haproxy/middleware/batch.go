@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"terraform-provider-haproxy-pf/haproxy/models"
+	"time"
+)
+
+// batchCommitTimeout bounds the start and the commit of a batch, which are
+// not tied to the context of any single operation.
+const batchCommitTimeout = 5 * time.Minute
+
+// transactionBatcher groups concurrent WithTransaction calls into a single
+// Data Plane transaction. The transaction is committed once no operation has
+// joined it for the configured window, so a whole terraform apply wave
+// results in one commit and one HAProxy reload.
+type transactionBatcher struct {
+	client *Client
+	window time.Duration
+
+	mu      sync.Mutex
+	current *transactionBatch
+}
+
+type transactionBatch struct {
+	// set before ready is closed, startErr when the transaction could not
+	// be started
+	transactionId string
+	startErr      error
+	ready         chan struct{}
+
+	// serializes the operations staged in the transaction
+	stageMu sync.Mutex
+
+	// guarded by transactionBatcher.mu
+	active     int
+	staged     int
+	generation int
+	// strongest reload mode requested by the operations, see reloadModeRank
+	reloadMode string
+
+	done chan struct{}
+	err  error
+}
+
+// EnableBatching makes WithTransaction share one transaction between all
+// operations started within window of each other.
+func (c *Client) EnableBatching(window time.Duration) {
+	c.batcher = &transactionBatcher{client: c, window: window}
+}
+
+// run stages fn in the shared transaction and waits for its commit. Once fn
+// is staged, the result of the commit is returned even if ctx is done in the
+// meantime: the change is committed with the batch anyway and reporting it
+// as failed would leave it unknown to Terraform.
+func (b *transactionBatcher) run(ctx context.Context, fn func(ctx context.Context, transactionId string) error) error {
+	batch, err := b.join(ctx)
+	if err != nil {
+		return err
+	}
+
+	batch.stageMu.Lock()
+	err = fn(ctx, batch.transactionId)
+	batch.stageMu.Unlock()
+
+	// a batch where nothing was staged is discarded
+	b.leave(batch, err == nil)
+	if errors.Is(err, ErrNothingToCommit) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	<-batch.done
+	return batch.err
+}
+
+// join returns the open batch, starting a new transaction if there is none.
+// The transaction is started without holding the batcher lock, operations
+// joining meanwhile wait for it.
+func (b *transactionBatcher) join(ctx context.Context) (*transactionBatch, error) {
+	b.mu.Lock()
+	batch := b.current
+	starting := batch == nil
+	if starting {
+		batch = &transactionBatch{
+			ready: make(chan struct{}),
+			done:  make(chan struct{}),
+		}
+		b.current = batch
+	}
+	batch.active++
+	batch.generation++
+	if mode := b.client.reloadModeOf(ctx); reloadModeRank[mode] >= reloadModeRank[batch.reloadMode] {
+		batch.reloadMode = mode
+	}
+	b.mu.Unlock()
+
+	if starting {
+		b.start(batch)
+	}
+	<-batch.ready
+	if batch.startErr != nil {
+		return nil, batch.startErr
+	}
+	return batch, nil
+}
+
+// start opens the transaction of batch, which is abandoned when it fails.
+func (b *transactionBatcher) start(batch *transactionBatch) {
+	defer close(batch.ready)
+
+	// the transaction outlives the operation starting it
+	ctx, cancel := context.WithTimeout(context.Background(), batchCommitTimeout)
+	defer cancel()
+
+	configuration, err := b.client.GetConfiguration(ctx)
+	if err == nil {
+		var transaction *models.Transaction
+		transaction, err = b.client.CreateTransaction(ctx, configuration.Version)
+		if err == nil {
+			batch.transactionId = transaction.Id
+			return
+		}
+	}
+
+	batch.startErr = err
+	b.mu.Lock()
+	if b.current == batch {
+		b.current = nil
+	}
+	b.mu.Unlock()
+}
+
+// reloadModeRank orders reload modes, a batch reloading as soon as one of
+// its operations requires it.
+var reloadModeRank = map[string]int{
+	ReloadModeSkip:    1,
+	ReloadModeDefault: 2,
+	ReloadModeForce:   3,
+}
+
+// leave marks an operation of batch as finished and schedules the commit
+// when it was the last running one.
+func (b *transactionBatcher) leave(batch *transactionBatch, staged bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch.active--
+	if staged {
+		batch.staged++
+	}
+	if batch.active == 0 {
+		generation := batch.generation
+		time.AfterFunc(b.window, func() { b.flush(batch, generation) })
+	}
+}
+
+// flush commits batch unless another operation joined it since the flush
+// was scheduled.
+func (b *transactionBatcher) flush(batch *transactionBatch, generation int) {
+	b.mu.Lock()
+	if b.current != batch || batch.active > 0 || batch.generation != generation {
+		b.mu.Unlock()
+		return
+	}
+	b.current = nil
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), batchCommitTimeout)
+	defer cancel()
+	ctx = WithReloadMode(ctx, batch.reloadMode)
+
+	if batch.staged == 0 {
+		// every operation failed, nothing to commit
+		b.client.discardTransaction(ctx, batch.transactionId)
+	} else if committed, err := b.client.CommitTransaction(ctx, batch.transactionId); err != nil {
+		b.client.discardTransaction(ctx, batch.transactionId)
+		batch.err = err
+	} else {
+		batch.err = b.client.waitForReload(ctx, committed.ReloadId)
+	}
+
+	close(batch.done)
+}
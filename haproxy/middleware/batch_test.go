@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newBatchTestClient returns a batching client of a Data Plane API whose
+// transactions are committed with commitStatus, and the count of requests
+// it received by method and path.
+func newBatchTestClient(t *testing.T, commitStatus int) (*Client, func(request string) int) {
+	var mu sync.Mutex
+	counts := map[string]int{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counts[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/v2/services/haproxy/configuration/raw":
+			_, _ = w.Write([]byte(`{"_version":1,"data":""}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/services/haproxy/transactions":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"_version":1,"id":"tx-1","status":"in_progress"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/services/haproxy/transactions/tx-1":
+			w.WriteHeader(commitStatus)
+			if commitStatus == http.StatusOK {
+				_, _ = w.Write([]byte(`{"_version":1,"id":"tx-1","status":"success"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"code":400,"message":"invalid configuration"}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	client.EnableBatching(50 * time.Millisecond)
+
+	return client, func(request string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[request]
+	}
+}
+
+// runBatched runs the operations concurrently and returns their errors in order.
+func runBatched(ctx context.Context, client *Client, operations ...func(ctx context.Context, transactionId string) error) []error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(operations))
+	for i, operation := range operations {
+		wg.Add(1)
+		go func(i int, operation func(ctx context.Context, transactionId string) error) {
+			defer wg.Done()
+			errs[i] = client.WithTransaction(ctx, operation)
+		}(i, operation)
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestBatchedTransactions(t *testing.T) {
+	client, count := newBatchTestClient(t, http.StatusOK)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- client.WithTransaction(context.Background(), func(ctx context.Context, transactionId string) error {
+				return client.DeleteBackend(ctx, transactionId, "be_test")
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := count("POST /v2/services/haproxy/transactions"); n != 1 {
+		t.Errorf("expected a single transaction, got %d", n)
+	}
+	if n := count("PUT /v2/services/haproxy/transactions/tx-1"); n != 1 {
+		t.Errorf("expected a single commit, got %d", n)
+	}
+	if n := count("DELETE /v2/services/haproxy/configuration/backends/be_test"); n != 5 {
+		t.Errorf("expected 5 staged operations, got %d", n)
+	}
+}
+
+func TestBatchStagingFailure(t *testing.T) {
+	client, count := newBatchTestClient(t, http.StatusOK)
+
+	stagingErr := errors.New("staging failed")
+	errs := runBatched(context.Background(), client,
+		func(ctx context.Context, transactionId string) error {
+			return client.DeleteBackend(ctx, transactionId, "be_test")
+		},
+		func(ctx context.Context, transactionId string) error {
+			return stagingErr
+		},
+	)
+
+	if errs[0] != nil || !errors.Is(errs[1], stagingErr) {
+		t.Fatalf("expected only the failed operation to fail, got %v", errs)
+	}
+	if n := count("PUT /v2/services/haproxy/transactions/tx-1"); n != 1 {
+		t.Errorf("expected the staged operation to be committed once, got %d commits", n)
+	}
+}
+
+func TestBatchNothingToCommit(t *testing.T) {
+	client, count := newBatchTestClient(t, http.StatusOK)
+
+	operation := func(ctx context.Context, transactionId string) error {
+		return ErrNothingToCommit
+	}
+	errs := runBatched(context.Background(), client, operation, operation)
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error for operation %d: %v", i, err)
+		}
+	}
+	// the batch is discarded once its window elapsed
+	deadline := time.Now().Add(time.Second)
+	for count("DELETE /v2/services/haproxy/transactions/tx-1") == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := count("DELETE /v2/services/haproxy/transactions/tx-1"); n != 1 {
+		t.Errorf("expected the empty transaction to be discarded, got %d deletes", n)
+	}
+	if n := count("PUT /v2/services/haproxy/transactions/tx-1"); n != 0 {
+		t.Errorf("expected no commit, got %d", n)
+	}
+}
+
+func TestBatchCommitFailure(t *testing.T) {
+	client, count := newBatchTestClient(t, http.StatusBadRequest)
+
+	operation := func(ctx context.Context, transactionId string) error {
+		return client.DeleteBackend(ctx, transactionId, "be_test")
+	}
+	errs := runBatched(context.Background(), client, operation, operation, operation)
+
+	for i, err := range errs {
+		if !IsValidation(err) {
+			t.Errorf("expected the commit error for operation %d, got %v", i, err)
+		}
+	}
+	if n := count("PUT /v2/services/haproxy/transactions/tx-1"); n != 1 {
+		t.Errorf("expected a single commit, got %d", n)
+	}
+	if n := count("DELETE /v2/services/haproxy/transactions/tx-1"); n != 1 {
+		t.Errorf("expected the failed transaction to be discarded, got %d", n)
+	}
+}
+
+func TestBatchCancelledAfterStaging(t *testing.T) {
+	client, count := newBatchTestClient(t, http.StatusOK)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := runBatched(ctx, client, func(ctx context.Context, transactionId string) error {
+		defer cancel()
+		return client.DeleteBackend(ctx, transactionId, "be_test")
+	})
+
+	if errs[0] != nil {
+		t.Errorf("expected the committed change to succeed, got %v", errs[0])
+	}
+	if n := count("PUT /v2/services/haproxy/transactions/tx-1"); n != 1 {
+		t.Errorf("expected a single commit, got %d", n)
+	}
+}
@@ -0,0 +1,618 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"terraform-provider-haproxy-pf/haproxy/models"
+	"testing"
+)
+
+// clientCall runs one Client method, returning its result or nil.
+type clientCall func(ctx context.Context, c *Client) (interface{}, error)
+
+func TestClientRequests(t *testing.T) {
+	backend := models.Backend{Name: "be1", Mode: "http", Balance: models.Balance{Algorithm: "roundrobin"}}
+	frontend := models.Frontend{Name: "fe1", Mode: "http", Maxconn: 100, DefaultBackend: "be1"}
+	server := models.Server{Name: "srv1", Address: "10.0.0.1", Port: 80, Check: "enabled"}
+	bind := models.Bind{Name: "http", Address: "0.0.0.0", Port: 80}
+	serverTemplate := models.ServerTemplate{Prefix: "web", Fqdn: "web.local", Num_or_range: "1-3", Port: 80}
+
+	backendQuery := url.Values{"backend": {"be1"}, "parent_name": {"be1"}, "parent_type": {"backend"}}
+	frontendQuery := url.Values{"frontend": {"fe1"}, "parent_name": {"fe1"}, "parent_type": {"frontend"}}
+	txQuery := url.Values{"transaction_id": {"tx1"}}
+	withTx := func(query url.Values) url.Values { return withValues(query, txQuery) }
+
+	tests := []struct {
+		name       string
+		apiVersion string
+		call       clientCall
+		method     string
+		path       string
+		query      url.Values
+		body       string
+		status     int
+		header     http.Header
+		response   string
+		want       string
+	}{
+		{
+			name:     "GetBackends",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetBackends(ctx) },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/backends",
+			response: `{"_version":4,"data":[{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}]}`,
+			want:     `{"_version":4,"data":[{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}]}`,
+		},
+		{
+			name:     "GetBackend",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetBackend(ctx, "be1") },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/backends/be1",
+			response: `{"_version":4,"data":{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}}`,
+			want:     `{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}`,
+		},
+		{
+			name:     "CreateBackend",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.CreateBackend(ctx, "tx1", backend) },
+			method:   "POST",
+			path:     "/v2/services/haproxy/configuration/backends",
+			query:    txQuery,
+			body:     `{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}`,
+			status:   http.StatusCreated,
+			response: `{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}`,
+			want:     `{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}`,
+		},
+		{
+			name: "UpdateBackend",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.UpdateBackend(ctx, "tx1", "be1", backend)
+			},
+			method:   "PUT",
+			path:     "/v2/services/haproxy/configuration/backends/be1",
+			query:    txQuery,
+			body:     `{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}`,
+			response: `{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}`,
+			want:     `{"name":"be1","mode":"http","balance":{"algorithm":"roundrobin"}}`,
+		},
+		{
+			name: "DeleteBackend",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.DeleteBackend(ctx, "tx1", "be1")
+			},
+			method: "DELETE",
+			path:   "/v2/services/haproxy/configuration/backends/be1",
+			query:  txQuery,
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "GetFrontends",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetFrontends(ctx) },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/frontends",
+			response: `{"_version":4,"data":[{"name":"fe1","mode":"http"}]}`,
+			want:     `{"_version":4,"data":[{"name":"fe1","mode":"http","maxconn":0,"http_connection_mode":"","default_backend":""}]}`,
+		},
+		{
+			name:     "GetFrontend",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetFrontend(ctx, "fe1") },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/frontends/fe1",
+			response: `{"_version":4,"data":{"name":"fe1","mode":"http","maxconn":100,"default_backend":"be1"}}`,
+			want:     `{"name":"fe1","mode":"http","maxconn":100,"http_connection_mode":"","default_backend":"be1"}`,
+		},
+		{
+			name: "CreateFrontend",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateFrontend(ctx, "tx1", frontend)
+			},
+			method:   "POST",
+			path:     "/v2/services/haproxy/configuration/frontends",
+			query:    txQuery,
+			body:     `{"name":"fe1","mode":"http","maxconn":100,"http_connection_mode":"","default_backend":"be1"}`,
+			status:   http.StatusCreated,
+			response: `{"name":"fe1","mode":"http","maxconn":100,"default_backend":"be1"}`,
+			want:     `{"name":"fe1","mode":"http","maxconn":100,"http_connection_mode":"","default_backend":"be1"}`,
+		},
+		{
+			name: "UpdateFrontend",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.UpdateFrontend(ctx, "tx1", "fe1", frontend)
+			},
+			method:   "PUT",
+			path:     "/v2/services/haproxy/configuration/frontends/fe1",
+			query:    txQuery,
+			body:     `{"name":"fe1","mode":"http","maxconn":100,"http_connection_mode":"","default_backend":"be1"}`,
+			response: `{"name":"fe1","mode":"http","maxconn":100,"default_backend":"be1"}`,
+			want:     `{"name":"fe1","mode":"http","maxconn":100,"http_connection_mode":"","default_backend":"be1"}`,
+		},
+		{
+			name: "DeleteFrontend",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.DeleteFrontend(ctx, "tx1", "fe1")
+			},
+			method: "DELETE",
+			path:   "/v2/services/haproxy/configuration/frontends/fe1",
+			query:  txQuery,
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "GetServers",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetServers(ctx, "be1") },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/servers",
+			query:    backendQuery,
+			response: `{"_version":4,"data":[{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}]}`,
+			want:     `{"_version":4,"data":[{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}]}`,
+		},
+		{
+			name:     "GetServer",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetServer(ctx, "srv1", "be1") },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/servers/srv1",
+			query:    backendQuery,
+			response: `{"_version":4,"data":{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}}`,
+			want:     `{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}`,
+		},
+		{
+			name: "CreateServer",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateServer(ctx, "tx1", server, "be1")
+			},
+			method:   "POST",
+			path:     "/v2/services/haproxy/configuration/servers",
+			query:    withTx(backendQuery),
+			body:     `{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}`,
+			status:   http.StatusCreated,
+			response: `{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}`,
+			want:     `{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}`,
+		},
+		{
+			name: "UpdateServer",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.UpdateServer(ctx, "tx1", server, "be1")
+			},
+			method:   "PUT",
+			path:     "/v2/services/haproxy/configuration/servers/srv1",
+			query:    withTx(backendQuery),
+			body:     `{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}`,
+			response: `{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}`,
+			want:     `{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}`,
+		},
+		{
+			name: "DeleteServer",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.DeleteServer(ctx, "tx1", "srv1", "be1")
+			},
+			method: "DELETE",
+			path:   "/v2/services/haproxy/configuration/servers/srv1",
+			query:  withTx(backendQuery),
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "GetBinds",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetBinds(ctx, "fe1") },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/binds",
+			query:    frontendQuery,
+			response: `{"_version":4,"data":[{"name":"http","address":"0.0.0.0","port":80}]}`,
+			want:     `{"_version":4,"data":[{"name":"http","address":"0.0.0.0","port":80}]}`,
+		},
+		{
+			name:     "GetBind",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetBind(ctx, "http", "fe1") },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/binds/http",
+			query:    frontendQuery,
+			response: `{"_version":4,"data":{"name":"http","address":"0.0.0.0","port":80}}`,
+			want:     `{"name":"http","address":"0.0.0.0","port":80}`,
+		},
+		{
+			name: "CreateBind",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateBind(ctx, "tx1", bind, "fe1")
+			},
+			method:   "POST",
+			path:     "/v2/services/haproxy/configuration/binds",
+			query:    withTx(frontendQuery),
+			body:     `{"name":"http","address":"0.0.0.0","port":80}`,
+			status:   http.StatusCreated,
+			response: `{"name":"http","address":"0.0.0.0","port":80}`,
+			want:     `{"name":"http","address":"0.0.0.0","port":80}`,
+		},
+		{
+			name: "UpdateBind",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.UpdateBind(ctx, "tx1", bind, "fe1")
+			},
+			method:   "PUT",
+			path:     "/v2/services/haproxy/configuration/binds/http",
+			query:    withTx(frontendQuery),
+			body:     `{"name":"http","address":"0.0.0.0","port":80}`,
+			response: `{"name":"http","address":"0.0.0.0","port":80}`,
+			want:     `{"name":"http","address":"0.0.0.0","port":80}`,
+		},
+		{
+			name: "DeleteBind",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.DeleteBind(ctx, "tx1", "http", "fe1")
+			},
+			method: "DELETE",
+			path:   "/v2/services/haproxy/configuration/binds/http",
+			query:  withTx(frontendQuery),
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "GetServerTemplates",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetServerTemplates(ctx, "be1") },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/server_templates",
+			query:    backendQuery,
+			response: `{"_version":4,"data":[{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80}]}`,
+			want:     `{"_version":4,"data":[{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80,"check":"","resolvers":""}]}`,
+		},
+		{
+			name: "GetServerTemplate",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetServerTemplate(ctx, "web", "be1")
+			},
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/server_templates/web",
+			query:    backendQuery,
+			response: `{"_version":4,"data":{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80}}`,
+			want:     `{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80,"check":"","resolvers":""}`,
+		},
+		{
+			name: "CreateServerTemplate",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateServerTemplate(ctx, "tx1", serverTemplate, "be1")
+			},
+			method:   "POST",
+			path:     "/v2/services/haproxy/configuration/server_templates",
+			query:    withTx(backendQuery),
+			body:     `{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80,"check":"","resolvers":""}`,
+			status:   http.StatusCreated,
+			response: `{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80}`,
+			want:     `{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80,"check":"","resolvers":""}`,
+		},
+		{
+			name: "UpdateServerTemplate",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.UpdateServerTemplate(ctx, "tx1", serverTemplate, "be1")
+			},
+			method:   "PUT",
+			path:     "/v2/services/haproxy/configuration/server_templates/web",
+			query:    withTx(backendQuery),
+			body:     `{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80,"check":"","resolvers":""}`,
+			response: `{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80}`,
+			want:     `{"prefix":"web","fqdn":"web.local","num_or_range":"1-3","port":80,"check":"","resolvers":""}`,
+		},
+		{
+			name: "DeleteServerTemplate",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.DeleteServerTemplate(ctx, "tx1", "web", "be1")
+			},
+			method: "DELETE",
+			path:   "/v2/services/haproxy/configuration/server_templates/web",
+			query:  withTx(backendQuery),
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "GetResolvers",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetResolvers(ctx) },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/resolvers",
+			response: `{"_version":4,"data":[{"name":"dns"}]}`,
+			want:     `{"_version":4,"data":[{"name":"dns"}]}`,
+		},
+		{
+			name:     "GetConfiguration",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetConfiguration(ctx) },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/raw",
+			response: `{"_version":4,"data":"global\n"}`,
+			want:     `{"_version":4,"data":"global\n"}`,
+		},
+		{
+			name:     "GetConfiguration unversioned",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetConfiguration(ctx) },
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/raw",
+			response: `{"data":""}`,
+			want:     `{"_version":1,"data":""}`,
+		},
+		{
+			name: "GetTransactionConfiguration",
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetTransactionConfiguration(ctx, "tx1")
+			},
+			method:   "GET",
+			path:     "/v2/services/haproxy/configuration/raw",
+			query:    txQuery,
+			response: `{"_version":4,"data":"backend be1\n"}`,
+			want:     `"backend be1\n"`,
+		},
+		{
+			name:     "CreateTransaction",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.CreateTransaction(ctx, 4) },
+			method:   "POST",
+			path:     "/v2/services/haproxy/transactions",
+			query:    url.Values{"version": {"4"}},
+			status:   http.StatusCreated,
+			response: `{"_version":4,"id":"tx1","status":"in_progress"}`,
+			want:     `{"_version":4,"id":"tx1","status":"in_progress"}`,
+		},
+		{
+			name:     "CommitTransaction",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.CommitTransaction(ctx, "tx1") },
+			method:   "PUT",
+			path:     "/v2/services/haproxy/transactions/tx1",
+			status:   http.StatusAccepted,
+			response: `{"_version":5,"id":"tx1","status":"success"}`,
+			want:     `{"_version":5,"id":"tx1","status":"success"}`,
+		},
+		{
+			name:   "DeleteTransaction",
+			call:   func(ctx context.Context, c *Client) (interface{}, error) { return nil, c.DeleteTransaction(ctx, "tx1") },
+			method: "DELETE",
+			path:   "/v2/services/haproxy/transactions/tx1",
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "TestApiCall",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return nil, c.TestApiCall(ctx) },
+			method:   "GET",
+			path:     "/v2/services/haproxy/stats/native",
+			response: `[]`,
+		},
+		{
+			name:     "GetInfo",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetInfo(ctx) },
+			method:   "GET",
+			path:     "/v2/info",
+			response: `{"api":{"version":"v2.6.1 abcdef","build_date":"2022-10-13T12:00:00Z"},"system":{"hostname":"lb1"}}`,
+			want:     `{"api":{"version":"v2.6.1 abcdef","build_date":"2022-10-13T12:00:00Z"},"system":{"hostname":"lb1"}}`,
+		},
+		{
+			name:     "GetProcessInfo",
+			call:     func(ctx context.Context, c *Client) (interface{}, error) { return c.GetProcessInfo(ctx) },
+			method:   "GET",
+			path:     "/v2/services/haproxy/runtime/info",
+			response: `[{"info":{"version":"2.6.5","release_date":"2022/10/08"}}]`,
+			want:     `{"info":{"version":"2.6.5","release_date":"2022/10/08"}}`,
+		},
+		{
+			name:       "GetBackend v3",
+			apiVersion: APIVersion3,
+			call:       func(ctx context.Context, c *Client) (interface{}, error) { return c.GetBackend(ctx, "be1") },
+			method:     "GET",
+			path:       "/v3/services/haproxy/configuration/backends/be1",
+			response:   `{"name":"be1","mode":"tcp","balance":{"algorithm":"source"}}`,
+			want:       `{"name":"be1","mode":"tcp","balance":{"algorithm":"source"}}`,
+		},
+		{
+			name:       "GetServers v3",
+			apiVersion: APIVersion3,
+			call:       func(ctx context.Context, c *Client) (interface{}, error) { return c.GetServers(ctx, "be1") },
+			method:     "GET",
+			path:       "/v3/services/haproxy/configuration/backends/be1/servers",
+			header:     http.Header{"Configuration-Version": {"7"}},
+			response:   `[{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}]`,
+			want:       `{"_version":7,"data":[{"name":"srv1","address":"10.0.0.1","port":80,"check":"enabled"}]}`,
+		},
+		{
+			name:       "CreateBind v3",
+			apiVersion: APIVersion3,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.CreateBind(ctx, "tx1", bind, "fe1")
+			},
+			method:   "POST",
+			path:     "/v3/services/haproxy/configuration/frontends/fe1/binds",
+			query:    txQuery,
+			body:     `{"name":"http","address":"0.0.0.0","port":80}`,
+			status:   http.StatusCreated,
+			response: `{"name":"http","address":"0.0.0.0","port":80}`,
+			want:     `{"name":"http","address":"0.0.0.0","port":80}`,
+		},
+		{
+			name:       "DeleteServerTemplate v3",
+			apiVersion: APIVersion3,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return nil, c.DeleteServerTemplate(ctx, "tx1", "web", "be1")
+			},
+			method: "DELETE",
+			path:   "/v3/services/haproxy/configuration/backends/be1/server_templates/web",
+			query:  txQuery,
+			status: http.StatusNoContent,
+		},
+		{
+			name:       "GetConfiguration v3",
+			apiVersion: APIVersion3,
+			call:       func(ctx context.Context, c *Client) (interface{}, error) { return c.GetConfiguration(ctx) },
+			method:     "GET",
+			path:       "/v3/services/haproxy/configuration/version",
+			response:   `7`,
+			want:       `{"_version":7,"data":""}`,
+		},
+		{
+			name:       "GetTransactionConfiguration v3",
+			apiVersion: APIVersion3,
+			call: func(ctx context.Context, c *Client) (interface{}, error) {
+				return c.GetTransactionConfiguration(ctx, "tx1")
+			},
+			method:   "GET",
+			path:     "/v3/services/haproxy/configuration/raw",
+			query:    txQuery,
+			response: "backend be1\n",
+			want:     `"backend be1\n"`,
+		},
+		{
+			name:       "GetProcessInfo v3",
+			apiVersion: APIVersion3,
+			call:       func(ctx context.Context, c *Client) (interface{}, error) { return c.GetProcessInfo(ctx) },
+			method:     "GET",
+			path:       "/v3/services/haproxy/runtime/info",
+			response:   `{"info":{"version":"3.0.2","release_date":"2024/06/14"}}`,
+			want:       `{"info":{"version":"3.0.2","release_date":"2024/06/14"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMethod, gotPath, gotBody string
+			var gotQuery url.Values
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotMethod, gotPath, gotQuery, gotBody = r.Method, r.URL.Path, r.URL.Query(), string(body)
+
+				status := tt.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				for name, values := range tt.header {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(tt.response))
+			})
+			if tt.apiVersion != "" {
+				if err := client.SetAPIVersion(tt.apiVersion); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := tt.call(context.Background(), client)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if gotMethod != tt.method || gotPath != tt.path {
+				t.Errorf("unexpected request %s %s, want %s %s", gotMethod, gotPath, tt.method, tt.path)
+			}
+			wantQuery := tt.query
+			if wantQuery == nil {
+				wantQuery = url.Values{}
+			}
+			if !reflect.DeepEqual(gotQuery, wantQuery) {
+				t.Errorf("unexpected query %v, want %v", gotQuery, wantQuery)
+			}
+			if tt.body == "" {
+				if gotBody != "" {
+					t.Errorf("unexpected request body %s", gotBody)
+				}
+			} else {
+				assertJSONEqual(t, "request body", gotBody, tt.body)
+			}
+			if tt.want != "" {
+				res, err := json.Marshal(got)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertJSONEqual(t, "result", string(res), tt.want)
+			}
+		})
+	}
+}
+
+func TestClientResponseStatus(t *testing.T) {
+	calls := map[string]clientCall{
+		"get": func(ctx context.Context, c *Client) (interface{}, error) {
+			return c.GetServer(ctx, "srv1", "be1")
+		},
+		"create": func(ctx context.Context, c *Client) (interface{}, error) {
+			return c.CreateServer(ctx, "tx1", models.Server{Name: "srv1"}, "be1")
+		},
+		"update": func(ctx context.Context, c *Client) (interface{}, error) {
+			return c.UpdateBackend(ctx, "tx1", "be1", models.Backend{Name: "be1"})
+		},
+		"delete": func(ctx context.Context, c *Client) (interface{}, error) {
+			return nil, c.DeleteBind(ctx, "tx1", "http", "fe1")
+		},
+	}
+
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		// nil when the call succeeds
+		check func(err error) bool
+	}{
+		{
+			name:   "no content",
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "not found",
+			status:   http.StatusNotFound,
+			response: `{"code":404,"message":"object not found"}`,
+			check:    IsNotFound,
+		},
+		{
+			name:     "conflict",
+			status:   http.StatusConflict,
+			response: `{"code":409,"message":"version mismatch"}`,
+			check:    IsConflict,
+		},
+		{
+			name:     "malformed error body",
+			status:   http.StatusInternalServerError,
+			response: `upstream crashed`,
+			check: func(err error) bool {
+				apiErr, ok := AsAPIError(err)
+				return ok && IsServerError(err) && apiErr.Message == "" && apiErr.Body == "upstream crashed"
+			},
+		},
+		{
+			name:     "malformed success body",
+			status:   http.StatusOK,
+			response: `{"name":`,
+			check: func(err error) bool {
+				_, ok := AsAPIError(err)
+				return err != nil && !ok
+			},
+		},
+	}
+
+	for callName, call := range calls {
+		for _, tt := range tests {
+			if callName == "delete" && tt.name == "malformed success body" {
+				// delete does not decode the response
+				continue
+			}
+			t.Run(callName+" "+tt.name, func(t *testing.T) {
+				client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(tt.response))
+				})
+
+				_, err := call(context.Background(), client)
+				if tt.check == nil {
+					if err != nil {
+						t.Errorf("unexpected error: %v", err)
+					}
+					return
+				}
+				if !tt.check(err) {
+					t.Errorf("unexpected error: %v", err)
+				}
+			})
+		}
+	}
+}
+
+func assertJSONEqual(t *testing.T, what string, got string, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal([]byte(got), &gotValue); err != nil {
+		t.Fatalf("%s is not JSON: %v: %s", what, err, got)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("invalid expected %s: %v", what, err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("unexpected %s:\n got %s\nwant %s", what, got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// nodeContextKey holds the cluster node an operation applies to.
+type nodeContextKey struct{}
+
+// SetCluster makes c the entry point of a cluster of HAProxy nodes sharing
+// the same configuration. c is usually nodes[0]. Changes made through
+// WithTransaction are applied to every node in its own transaction and
+// succeed when at least quorum nodes committed them. Reads of single objects
+// query every node and report the nodes that diverge, see WithDriftReport.
+func (c *Client) SetCluster(nodes []*Client, quorum int) error {
+	if len(nodes) == 0 {
+		return errors.New("a cluster needs at least one node")
+	}
+	if quorum < 1 || quorum > len(nodes) {
+		return fmt.Errorf("quorum must be between 1 and %d, got %d", len(nodes), quorum)
+	}
+	c.nodes = nodes
+	c.quorum = quorum
+	return nil
+}
+
+// Nodes returns the nodes of the cluster, or nil outside of cluster mode.
+func (c *Client) Nodes() []*Client {
+	return c.nodes
+}
+
+// clustered reports whether an operation on ctx must fan out to the nodes,
+// which is not the case once it runs on a single node.
+func (c *Client) clustered(ctx context.Context) bool {
+	_, onNode := ctx.Value(nodeContextKey{}).(*Client)
+	return len(c.nodes) > 0 && !onNode
+}
+
+// target returns the node an operation on ctx applies to, c outside of a
+// cluster transaction.
+func (c *Client) target(ctx context.Context) *Client {
+	if node, ok := ctx.Value(nodeContextKey{}).(*Client); ok {
+		return node
+	}
+	return c
+}
+
+// clusterTransaction runs fn on every node, each in its own retried
+// transaction, nodes being processed one after the other.
+func (c *Client) clusterTransaction(ctx context.Context, fn func(ctx context.Context, transactionId string) error) error {
+	clusterErr := &ClusterError{Quorum: c.quorum, Nodes: len(c.nodes), Errors: map[string]error{}}
+	for _, node := range c.nodes {
+		nodeCtx := context.WithValue(ctx, nodeContextKey{}, node)
+		err := node.Retry(nodeCtx, func() error {
+			return node.WithTransaction(nodeCtx, fn)
+		})
+		if err != nil {
+			tflog.Warn(ctx, "Could not apply change on Haproxy node", map[string]any{
+				"node":  node.Host(),
+				"error": err.Error(),
+			})
+			clusterErr.Errors[node.Host()] = err
+		}
+	}
+
+	if len(c.nodes)-len(clusterErr.Errors) < c.quorum {
+		return clusterErr
+	}
+	return nil
+}
+
+// ClusterError is returned when a change could not be committed on a quorum of nodes.
+type ClusterError struct {
+	Quorum int
+	Nodes  int
+	// errors by node host
+	Errors map[string]error
+}
+
+func (e *ClusterError) Error() string {
+	hosts := make([]string, 0, len(e.Errors))
+	for host := range e.Errors {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	details := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		details = append(details, host+": "+e.Errors[host].Error())
+	}
+	return fmt.Sprintf("change applied on %d of %d nodes, quorum is %d: %s",
+		e.Nodes-len(e.Errors), e.Nodes, e.Quorum, strings.Join(details, "; "))
+}
+
+// Is allows errors.Is(err, ErrNotFound) to match a change failing on every
+// node because the object does not exist.
+func (e *ClusterError) Is(target error) bool {
+	if target != ErrNotFound || len(e.Errors) != e.Nodes {
+		return false
+	}
+	for _, err := range e.Errors {
+		if !IsNotFound(err) {
+			return false
+		}
+	}
+	return true
+}
+
+// Drift describes a cluster node whose object differs from the one returned by a read.
+type Drift struct {
+	Node   string
+	Object string
+	Detail string
+}
+
+// DriftReport collects the drifts found by the reads made with its context.
+type DriftReport struct {
+	mu     sync.Mutex
+	drifts []Drift
+}
+
+type driftContextKey struct{}
+
+// WithDriftReport returns a context collecting into the returned report the
+// nodes diverging on the objects read with it.
+func WithDriftReport(ctx context.Context) (context.Context, *DriftReport) {
+	report := &DriftReport{}
+	return context.WithValue(ctx, driftContextKey{}, report), report
+}
+
+// Drifts returns the drifts found so far.
+func (r *DriftReport) Drifts() []Drift {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Drift(nil), r.drifts...)
+}
+
+func reportDrift(ctx context.Context, drift Drift) {
+	tflog.Warn(ctx, "Haproxy node drift", map[string]any{
+		"node":   drift.Node,
+		"object": drift.Object,
+		"detail": drift.Detail,
+	})
+	if report, ok := ctx.Value(driftContextKey{}).(*DriftReport); ok {
+		report.mu.Lock()
+		report.drifts = append(report.drifts, drift)
+		report.mu.Unlock()
+	}
+}
+
+// clusterGet reads an object on every node. The object of the first node
+// having it is returned, the other nodes are compared to it.
+func (o objectClient[T]) clusterGet(ctx context.Context, parentName string, name string) (*T, error) {
+	nodes := o.client.nodes
+	results := make([]*T, len(nodes))
+	errs := make([]error, len(nodes))
+	reference := -1
+	for i, node := range nodes {
+		results[i], errs[i] = o.get(context.WithValue(ctx, nodeContextKey{}, node), parentName, name)
+		if errs[i] == nil && reference < 0 {
+			reference = i
+		}
+	}
+	if reference < 0 {
+		return nil, errs[0]
+	}
+
+	object := o.kind + "/" + name
+	if parentName != "" {
+		object = o.kind + "/" + parentName + "/" + name
+	}
+	want, _ := json.Marshal(results[reference])
+	for i, node := range nodes {
+		switch {
+		case i == reference:
+		case IsNotFound(errs[i]):
+			reportDrift(ctx, Drift{Node: node.Host(), Object: object, Detail: "object is missing"})
+		case errs[i] != nil:
+			reportDrift(ctx, Drift{Node: node.Host(), Object: object, Detail: "cannot read object: " + errs[i].Error()})
+		default:
+			got, _ := json.Marshal(results[i])
+			if !bytes.Equal(got, want) {
+				reportDrift(ctx, Drift{Node: node.Host(), Object: object, Detail: fmt.Sprintf("object is %s, %s has %s", got, nodes[reference].Host(), want)})
+			}
+		}
+	}
+	return results[reference], nil
+}
@@ -0,0 +1,112 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"terraform-provider-haproxy-pf/haproxy/dataplanetest"
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+	"terraform-provider-haproxy-pf/haproxy/models"
+)
+
+func newCluster(t *testing.T, size int, quorum int) (*middleware.Client, []*dataplanetest.Server) {
+	t.Helper()
+	servers := make([]*dataplanetest.Server, 0, size)
+	nodes := make([]*middleware.Client, 0, size)
+	for i := 0; i < size; i++ {
+		server := dataplanetest.NewServer(middleware.APIVersion2)
+		t.Cleanup(server.Close)
+		servers = append(servers, server)
+
+		node := middleware.NewClient(dataplanetest.Username, dataplanetest.Password, server.Host(), true)
+		node.RetryPolicy = middleware.RetryPolicy{Attempts: 2, Delay: time.Millisecond}
+		nodes = append(nodes, node)
+	}
+	if err := nodes[0].SetCluster(nodes, quorum); err != nil {
+		t.Fatal(err)
+	}
+	return nodes[0], servers
+}
+
+func createBackend(client *middleware.Client, backend models.Backend) error {
+	return client.WithTransaction(context.Background(), func(ctx context.Context, transactionId string) error {
+		_, err := client.CreateBackend(ctx, transactionId, backend)
+		return err
+	})
+}
+
+func TestClusterTransaction(t *testing.T) {
+	client, servers := newCluster(t, 3, 3)
+
+	if err := createBackend(client, models.Backend{Name: "be_web", Mode: "http"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, server := range servers {
+		if _, ok := server.Object("backends", "", "be_web"); !ok {
+			t.Errorf("backend missing on node %d", i)
+		}
+	}
+
+	err := client.WithTransaction(context.Background(), func(ctx context.Context, transactionId string) error {
+		return client.DeleteBackend(ctx, transactionId, "be_missing")
+	})
+	if !middleware.IsNotFound(err) {
+		t.Errorf("expected not found on every node, got %v", err)
+	}
+}
+
+func TestClusterQuorum(t *testing.T) {
+	tests := []struct {
+		name    string
+		quorum  int
+		wantErr bool
+	}{
+		{"quorum reached", 2, false},
+		{"quorum missed", 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, servers := newCluster(t, 3, tt.quorum)
+			servers[1].PutObject("backends", "", map[string]interface{}{"name": "be_web"})
+
+			err := createBackend(client, models.Backend{Name: "be_web"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if clusterErr, ok := err.(*middleware.ClusterError); tt.wantErr && (!ok || len(clusterErr.Errors) != 1) {
+				t.Errorf("expected a cluster error for one node, got %#v", err)
+			}
+			if middleware.IsRetryable(err) {
+				t.Error("quorum failures must not be retried as a whole")
+			}
+		})
+	}
+}
+
+func TestClusterDrift(t *testing.T) {
+	client, servers := newCluster(t, 3, 3)
+	if err := createBackend(client, models.Backend{Name: "be_web", Mode: "http"}); err != nil {
+		t.Fatal(err)
+	}
+	servers[1].PutObject("backends", "", map[string]interface{}{"name": "be_web", "mode": "tcp"})
+	servers[2].DeleteObject("backends", "", "be_web")
+
+	ctx, report := middleware.WithDriftReport(context.Background())
+	backend, err := client.GetBackend(ctx, "be_web")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if backend.Mode != "http" {
+		t.Errorf("expected the backend of the first node, got %+v", backend)
+	}
+
+	drifts := report.Drifts()
+	if len(drifts) != 2 || drifts[0].Node != servers[1].Host() || drifts[1].Node != servers[2].Host() {
+		t.Fatalf("unexpected drifts %+v", drifts)
+	}
+	if drifts[1].Detail != "object is missing" {
+		t.Errorf("unexpected drift detail %q", drifts[1].Detail)
+	}
+}
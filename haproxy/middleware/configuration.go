@@ -1,13 +1,19 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"terraform-provider-haproxy-pf/haproxy/models"
 )
 
-func (c *Client) GetConfiguration() (*models.Configuration, error) {
-	url := c.base_url + "/services/haproxy/configuration/raw"
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) GetConfiguration(ctx context.Context) (*models.Configuration, error) {
+	if c.apiVersion == APIVersion3 {
+		// v3 returns the raw configuration as plain text, only fetch its version
+		return c.getConfigurationVersion(ctx)
+	}
+
+	url := c.apiURL(configurationPath+"raw", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -23,3 +29,22 @@ func (c *Client) GetConfiguration() (*models.Configuration, error) {
 
 	return &res, nil
 }
+
+func (c *Client) getConfigurationVersion(ctx context.Context) (*models.Configuration, error) {
+	url := c.apiURL(configurationPath+"version", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := models.Configuration{}
+	if err := c.sendRequest(req, &res.Version); err != nil {
+		return nil, err
+	}
+
+	if res.Version == 0 {
+		res.Version = 1
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var ErrNotFound = errors.New("NotFound")
+
+// maxErrorBodyLength bounds the raw response body kept on an APIError.
+const maxErrorBodyLength = 512
+
+// APIError is returned for every Data Plane API response with a status code >= 300.
+type APIError struct {
+	// HTTP status code of the response
+	StatusCode int
+	// error code reported by the Data Plane API, 0 if the body could not be decoded
+	Code int
+	// error message reported by the Data Plane API
+	Message string
+	Method  string
+	URL     string
+	// excerpt of the raw response body
+	Body string
+}
+
+func newAPIError(req *http.Request, statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Method:     req.Method,
+		URL:        req.URL.Redacted(),
+		Body:       strings.TrimSpace(truncate(string(body), maxErrorBodyLength)),
+	}
+
+	var errRes errorResponse
+	if err := decodeJSON(body, &errRes); err == nil {
+		apiErr.Code = errRes.Code
+		apiErr.Message = errRes.Message
+	}
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = e.Body
+	}
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s returned status %d: %s", e.Method, e.URL, e.StatusCode, message)
+}
+
+// Is allows errors.Is(err, ErrNotFound) to match a 404 response.
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// AsAPIError returns the APIError wrapped in err, if any.
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsNotFound reports whether err is a 404 returned by the Data Plane API.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict reports whether err is a 409, e.g. a configuration version mismatch.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// versionConflictMessages are fragments of the messages of the 409 responses
+// reporting a change of the configuration version, as opposed to conflicts
+// with existing objects such as "already exists".
+var versionConflictMessages = []string{"version mismatch", "given version", "outdated"}
+
+// IsVersionConflict reports whether err is a 409 caused by a configuration
+// version mismatch or an outdated transaction, which a new attempt on the
+// current version may resolve.
+func IsVersionConflict(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	if !ok || apiErr.StatusCode != http.StatusConflict {
+		return false
+	}
+	message := strings.ToLower(apiErr.Message)
+	if message == "" {
+		message = strings.ToLower(apiErr.Body)
+	}
+	for _, fragment := range versionConflictMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidation reports whether err is a request rejected by the Data Plane API
+// because the submitted payload or configuration is invalid.
+func IsValidation(err error) bool {
+	return hasStatus(err, http.StatusBadRequest, http.StatusUnprocessableEntity)
+}
+
+// IsUnauthorized reports whether err is an authentication or authorization failure.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized, http.StatusForbidden)
+}
+
+// IsServerError reports whether err is a 5xx returned by the Data Plane API.
+func IsServerError(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.StatusCode >= 500
+}
+
+func hasStatus(err error, statusCodes ...int) bool {
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		return false
+	}
+	for _, statusCode := range statusCodes {
+		if apiErr.StatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
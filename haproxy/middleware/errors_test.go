@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient("admin", "adminpwd", strings.TrimPrefix(server.URL, "http://"), true)
+}
+
+func TestSendRequestAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		code       int
+		message    string
+		notFound   bool
+		conflict   bool
+		validation bool
+	}{
+		{
+			name:     "not found",
+			status:   http.StatusNotFound,
+			body:     `{"code":404,"message":"missing object"}`,
+			code:     404,
+			message:  "missing object",
+			notFound: true,
+		},
+		{
+			name:     "version conflict",
+			status:   http.StatusConflict,
+			body:     `{"code":409,"message":"version mismatch"}`,
+			code:     409,
+			message:  "version mismatch",
+			conflict: true,
+		},
+		{
+			name:       "validation",
+			status:     http.StatusBadRequest,
+			body:       `{"code":400,"message":"invalid balance algorithm"}`,
+			code:       400,
+			message:    "invalid balance algorithm",
+			validation: true,
+		},
+		{
+			name:   "malformed body",
+			status: http.StatusBadGateway,
+			body:   `<html>bad gateway</html>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			_, err := client.GetBackend(context.Background(), "be_test")
+			apiErr, ok := AsAPIError(err)
+			if !ok {
+				t.Fatalf("expected *APIError, got %T: %v", err, err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Code != tt.code || apiErr.Message != tt.message {
+				t.Errorf("unexpected error fields: %+v", apiErr)
+			}
+			if apiErr.Method != http.MethodGet || !strings.HasSuffix(apiErr.URL, "/v2/services/haproxy/configuration/backends/be_test") {
+				t.Errorf("unexpected request in error: %s %s", apiErr.Method, apiErr.URL)
+			}
+			if apiErr.Body != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, apiErr.Body)
+			}
+			if IsNotFound(err) != tt.notFound || errors.Is(err, ErrNotFound) != tt.notFound {
+				t.Errorf("IsNotFound = %v, want %v", IsNotFound(err), tt.notFound)
+			}
+			if IsConflict(err) != tt.conflict {
+				t.Errorf("IsConflict = %v, want %v", IsConflict(err), tt.conflict)
+			}
+			if IsValidation(err) != tt.validation {
+				t.Errorf("IsValidation = %v, want %v", IsValidation(err), tt.validation)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// healthCheckTimeout bounds the probe of an instance during a failover.
+const healthCheckTimeout = 5 * time.Second
+
+// SetHosts replaces the Data Plane API instances reached by the client. The
+// first one is preferred: requests stick to the instance in use until it
+// becomes unreachable, then the instances are probed in order and the first
+// healthy one takes over.
+func (c *Client) SetHosts(hosts []string) error {
+	if len(hosts) == 0 {
+		return errors.New("at least one Data Plane API host is required")
+	}
+	endpoints := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		endpoints = append(endpoints, c.scheme+"://"+host)
+	}
+
+	c.endpointMu.Lock()
+	defer c.endpointMu.Unlock()
+	c.endpoints = endpoints
+	c.current = 0
+	return nil
+}
+
+// Host returns the host of the Data Plane API instance in use.
+func (c *Client) Host() string {
+	return strings.TrimPrefix(c.serverURL(), c.scheme+"://")
+}
+
+// serverURL returns the base URL of the Data Plane API instance in use.
+func (c *Client) serverURL() string {
+	c.endpointMu.Lock()
+	defer c.endpointMu.Unlock()
+	return c.endpoints[c.current]
+}
+
+// failover switches to the first healthy instance after req failed to reach
+// its own, and returns req addressed to that instance. It is false when
+// there is no other healthy instance. The instances are probed without
+// holding endpointMu, so requests on the instance in use are not blocked.
+func (c *Client) failover(ctx context.Context, req *http.Request) (*http.Request, bool) {
+	if ctx.Err() != nil {
+		return nil, false
+	}
+
+	c.endpointMu.Lock()
+	endpoints := c.endpoints
+	current := c.endpoints[c.current]
+	c.endpointMu.Unlock()
+
+	if len(endpoints) < 2 {
+		return nil, false
+	}
+
+	reqURL := req.URL.String()
+	failed := ""
+	for _, endpoint := range endpoints {
+		if strings.HasPrefix(reqURL, endpoint+"/") {
+			failed = endpoint
+		}
+	}
+	if failed == "" {
+		return nil, false
+	}
+
+	// another request may already have switched instance
+	next := current
+	if next == failed {
+		next = ""
+		for _, endpoint := range endpoints {
+			if endpoint != failed && c.healthy(ctx, endpoint) {
+				next = c.switchEndpoint(ctx, failed, endpoint)
+				break
+			}
+		}
+	}
+	if next == "" {
+		return nil, false
+	}
+
+	retryURL, err := url.Parse(next + strings.TrimPrefix(reqURL, failed))
+	if err != nil {
+		return nil, false
+	}
+	retryReq := req.Clone(req.Context())
+	retryReq.URL = retryURL
+	retryReq.Host = ""
+	return retryReq, true
+}
+
+// switchEndpoint makes endpoint the instance in use if failed still is, and
+// returns the instance in use, e.g. the one another failover switched to
+// while the instances were probed.
+func (c *Client) switchEndpoint(ctx context.Context, failed string, endpoint string) string {
+	c.endpointMu.Lock()
+	defer c.endpointMu.Unlock()
+
+	if c.endpoints[c.current] != failed {
+		return c.endpoints[c.current]
+	}
+	for i, candidate := range c.endpoints {
+		if candidate == endpoint {
+			tflog.Warn(ctx, "Haproxy Data Plane API unreachable, switching instance", map[string]any{
+				"failed_endpoint": failed,
+				"endpoint":        endpoint,
+			})
+			c.current = i
+			return endpoint
+		}
+	}
+	// the instances were replaced meanwhile
+	return ""
+}
+
+// healthy probes the info endpoint of an instance, which is healthy when it
+// answers without a server error.
+func (c *Client) healthy(ctx context.Context, endpoint string) bool {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"/"+c.apiVersion+"/info", nil)
+	if err != nil {
+		return false
+	}
+	c.setHeaders(req)
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return false
+	}
+	res.Body.Close()
+	return res.StatusCode < http.StatusInternalServerError
+}
@@ -0,0 +1,152 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"terraform-provider-haproxy-pf/haproxy/dataplanetest"
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+	"terraform-provider-haproxy-pf/haproxy/models"
+)
+
+// instance is a fake Data Plane API which can be made unreachable.
+type instance struct {
+	*dataplanetest.Server
+	down int32
+}
+
+func newInstance(t *testing.T) *instance {
+	t.Helper()
+	i := &instance{Server: dataplanetest.NewServer(middleware.APIVersion2)}
+	t.Cleanup(i.Close)
+
+	handler := i.Config.Handler
+	i.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&i.down) == 1 {
+			// drop the connection without answering
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+	return i
+}
+
+func (i *instance) setDown(down bool) {
+	value := int32(0)
+	if down {
+		value = 1
+	}
+	atomic.StoreInt32(&i.down, value)
+}
+
+func TestFailover(t *testing.T) {
+	primary, secondary := newInstance(t), newInstance(t)
+	ctx := context.Background()
+
+	client := middleware.NewClient(dataplanetest.Username, dataplanetest.Password, primary.Host(), true)
+	if err := client.SetHosts([]string{primary.Host(), secondary.Host()}); err != nil {
+		t.Fatal(err)
+	}
+	client.HTTPClient.Transport.(*http.Transport).DisableKeepAlives = true
+
+	primary.setDown(true)
+	if _, err := client.GetBackends(ctx); err != nil {
+		t.Fatalf("read during failover: %v", err)
+	}
+	if client.Host() != secondary.Host() {
+		t.Errorf("using %s, want secondary %s", client.Host(), secondary.Host())
+	}
+
+	// sticky: the secondary stays in use once the primary is back
+	primary.setDown(false)
+	err := client.Retry(ctx, func() error {
+		return client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
+			_, err := client.CreateBackend(ctx, transactionId, models.Backend{Name: "be_web"})
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, ok := secondary.Object("backends", "", "be_web"); !ok {
+		t.Error("backend not written to the secondary")
+	}
+
+	// writes failing over are retried as a whole on the preferred instance
+	secondary.setDown(true)
+	err = client.Retry(ctx, func() error {
+		return client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
+			_, err := client.CreateBackend(ctx, transactionId, models.Backend{Name: "be_api"})
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("write during failover: %v", err)
+	}
+	if client.Host() != primary.Host() {
+		t.Errorf("using %s, want primary %s", client.Host(), primary.Host())
+	}
+	if _, ok := primary.Object("backends", "", "be_api"); !ok {
+		t.Error("backend not written to the primary")
+	}
+
+	primary.setDown(true)
+	if _, err := client.GetBackends(ctx); err == nil {
+		t.Error("expected an error with every instance down")
+	}
+}
+
+func TestFailoverProbeDoesNotBlock(t *testing.T) {
+	primary, secondary := newInstance(t), newInstance(t)
+
+	// the probe of the secondary hangs until released
+	probing, release := make(chan struct{}), make(chan struct{})
+	handler := secondary.Config.Handler
+	secondary.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/info" {
+			close(probing)
+			<-release
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	client := middleware.NewClient(dataplanetest.Username, dataplanetest.Password, primary.Host(), true)
+	if err := client.SetHosts([]string{primary.Host(), secondary.Host()}); err != nil {
+		t.Fatal(err)
+	}
+	client.HTTPClient.Transport.(*http.Transport).DisableKeepAlives = true
+	primary.setDown(true)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := client.GetBackends(context.Background())
+		errs <- err
+	}()
+	<-probing
+
+	hosts := make(chan string, 1)
+	go func() { hosts <- client.Host() }()
+	select {
+	case host := <-hosts:
+		if host != primary.Host() {
+			t.Errorf("using %s during the probe, want primary %s", host, primary.Host())
+		}
+	case <-time.After(time.Second):
+		t.Error("Host blocked by the probe of the secondary")
+	}
+
+	close(release)
+	if err := <-errs; err != nil {
+		t.Fatalf("read during failover: %v", err)
+	}
+	if client.Host() != secondary.Host() {
+		t.Errorf("using %s, want secondary %s", client.Host(), secondary.Host())
+	}
+}
@@ -1,90 +1,37 @@
 package middleware
 
 import (
-	"bytes"
-	"encoding/json"
-	"net/http"
+	"context"
 	"terraform-provider-haproxy-pf/haproxy/models"
 )
 
+func (c *Client) frontends() objectClient[models.Frontend] {
+	return sectionObjects[models.Frontend](c, "frontends")
+}
+
 // return all frontends
-func (c *Client) GetFrontends() (*models.GetFrontends, error) {
-	url := c.base_url + "/services/haproxy/configuration/frontends/"
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) GetFrontends(ctx context.Context) (*models.GetFrontends, error) {
+	data, version, err := c.frontends().list(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 
-	res := models.GetFrontends{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+	return &models.GetFrontends{Version: version, Data: data}, nil
 }
 
 // return single frontend
-func (c *Client) GetFrontend(frontendName string) (*models.Frontend, error) {
-	url := c.base_url + "/services/haproxy/configuration/frontends/" + frontendName
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	res := models.GetFrontend{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res.Data, nil
+func (c *Client) GetFrontend(ctx context.Context, frontendName string) (*models.Frontend, error) {
+	return c.frontends().get(ctx, "", frontendName)
 }
 
-func (c *Client) CreateFrontend(transactionId string, frontend models.Frontend) (*models.Frontend, error) {
-	url := c.base_url + "/services/haproxy/configuration/frontends?transaction_id=" + transactionId
-	bodyStr, _ := json.Marshal(frontend)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyStr))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	res := models.Frontend{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+func (c *Client) CreateFrontend(ctx context.Context, transactionId string, frontend models.Frontend) (*models.Frontend, error) {
+	return c.frontends().create(ctx, transactionId, "", frontend)
 }
 
-func (c *Client) UpdateFrontend(transactionId string, frontendName string, frontend models.Frontend) (*models.Frontend, error) {
-	url := c.base_url + "/services/haproxy/configuration/frontends/" + frontendName + "?transaction_id=" + transactionId
-	bodyStr, _ := json.Marshal(frontend)
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(bodyStr))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	res := models.Frontend{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+func (c *Client) UpdateFrontend(ctx context.Context, transactionId string, frontendName string, frontend models.Frontend) (*models.Frontend, error) {
+	return c.frontends().update(ctx, transactionId, "", frontendName, frontend)
 }
 
-func (c *Client) DeleteFrontend(transactionId string, frontendName string) error {
-	url := c.base_url + "/services/haproxy/configuration/frontends/" + frontendName + "?transaction_id=" + transactionId
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return err
-	}
-
-	if err := c.sendRequest(req, nil); err != nil {
-		return err
-	}
-
-	return nil
+func (c *Client) DeleteFrontend(ctx context.Context, transactionId string, frontendName string) error {
+	return c.frontends().delete(ctx, transactionId, "", frontendName)
 }
@@ -1,35 +1,75 @@
 package middleware
 
 import (
+	"bytes"
 	"encoding/json"
-	"errors"
-	"fmt"
+	"io"
 	"net/http"
+	"sync"
 	"time"
 )
 
 type Client struct {
 	username   string
 	password   string
-	base_url   string
+	scheme     string
+	apiVersion string
 	HTTPClient *http.Client
-}
 
-var ErrNotFound = errors.New("NotFound")
+	// versions found by Negotiate and DetectHAProxyVersion
+	dataplaneVersion string
+	haproxyVersion   string
+
+	// RetryPolicy is used by Retry, see DefaultRetryPolicy
+	RetryPolicy RetryPolicy
+
+	batcher *transactionBatcher
+
+	// base URLs of the Data Plane API instances, see SetHosts
+	endpointMu sync.Mutex
+	endpoints  []string
+	current    int
+
+	// see SetCluster
+	nodes  []*Client
+	quorum int
+
+	// see WaitForReloads and SetReloadMode
+	reloadTimeout time.Duration
+	reloadMode    string
+
+	// see SetConcurrencyLimit, SetRateLimit and SerializeTransactions
+	requestSlots    chan struct{}
+	rateLimiter     *rateLimiter
+	transactionLock chan struct{}
+
+	// see SetValidateOnPlan and SetStrictReferences
+	validateOnPlan   bool
+	strictReferences bool
+
+	// see SetUserAgent and SetHeaders
+	userAgent string
+	headers   map[string]string
+}
 
 func NewClient(username string, password string, server_url string, insecure bool) *Client {
 	scheme := "https"
 	if insecure {
 		scheme = "http"
 	}
-	return &Client{
+	c := &Client{
 		username: username,
 		password: password,
 		HTTPClient: &http.Client{
-			Timeout: 5 * time.Minute,
+			Timeout:   5 * time.Minute,
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
 		},
-		base_url: scheme + "://" + server_url + "/v2",
+		scheme:      scheme,
+		endpoints:   []string{scheme + "://" + server_url},
+		RetryPolicy: DefaultRetryPolicy(),
 	}
+	_ = c.SetAPIVersion(APIVersion2)
+	return c
 }
 
 type errorResponse struct {
@@ -38,43 +78,80 @@ type errorResponse struct {
 }
 
 func (c *Client) sendRequest(req *http.Request, v interface{}) error {
-	req.Header.Set("Accept", "application/json; charset=utf-8")
-	req.Header.Set("Authorization", "Basic "+basicAuth(c.username, c.password))
+	_, err := c.sendRequestHeader(req, v)
+	return err
+}
+
+// sendRequestHeader is sendRequest also returning the response headers.
+func (c *Client) sendRequestHeader(req *http.Request, v interface{}) (http.Header, error) {
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json; charset=utf-8")
+	}
+	c.setHeaders(req)
+
+	release, err := c.acquireRequest(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx := logContext(req.Context())
+	logRequest(ctx, req)
+	start := time.Now()
 
 	res, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return err
+		logTransportError(ctx, req, err, time.Since(start))
+		// reads are sent again to the instance taking over, writes are
+		// left to Retry since a transaction only exists on its instance
+		retryReq, ok := c.failover(ctx, req)
+		if !ok || req.Method != http.MethodGet {
+			return nil, err
+		}
+		req = retryReq
+		logRequest(ctx, req)
+		start = time.Now()
+		if res, err = c.HTTPClient.Do(req); err != nil {
+			logTransportError(ctx, req, err, time.Since(start))
+			return nil, err
+		}
 	}
 
 	defer res.Body.Close()
 
-	if res.StatusCode == http.StatusNotFound {
-		// Latest version of haproxy API return 404 now instead of 204 before.
-		return ErrNotFound
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
 	}
+	logResponse(ctx, req, res, body, time.Since(start))
 
-	// Try to unmarshal into errorResponse
+	// Latest version of haproxy API return 404 now instead of 204 before,
+	// APIError matches ErrNotFound in that case.
 	if res.StatusCode >= 300 {
-		var errRes errorResponse
-		if err = json.NewDecoder(res.Body).Decode(&errRes); err == nil {
-			return errors.New(errRes.Message)
-		}
-
-		return fmt.Errorf("unknown error, status code: %d", res.StatusCode)
+		return res.Header, newAPIError(req, res.StatusCode, body)
 	}
 
 	if res.StatusCode == http.StatusNoContent {
-		return nil
+		return res.Header, nil
 	}
 
 	if v == nil {
-		return nil
+		return res.Header, nil
+	}
+
+	if raw, ok := v.(*[]byte); ok {
+		*raw = body
+		return res.Header, nil
 	}
 
-	if err = json.NewDecoder(res.Body).Decode(&v); err != nil {
-		return err
+	if err = decodeJSON(body, &v); err != nil {
+		return res.Header, err
 	}
 
-	return nil
+	return res.Header, nil
+
+}
 
+func decodeJSON(body []byte, v interface{}) error {
+	return json.NewDecoder(bytes.NewReader(body)).Decode(v)
 }
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// CreateChildId returns the ID of an object nested in a parent section, such
+// as a server of a backend: parent_type/parent_name/name, each part being
+// path escaped so that names can contain slashes.
+func CreateChildId(parentType string, parentName string, name string) string {
+	return strings.Join([]string{
+		url.PathEscape(parentType),
+		url.PathEscape(parentName),
+		url.PathEscape(name),
+	}, "/")
+}
+
+// ParseChildId parses the ID of an object nested in a parentType section,
+// returning the parent name and the name of the object. Besides the IDs of
+// CreateChildId it accepts the legacy IDs created by CreateResourceId, which
+// resources replace when they refresh. A legacy ID is parent_name/name with
+// both names unescaped, so it is ambiguous when a name contains a slash:
+// such IDs are rejected rather than split at a guessed slash.
+func ParseChildId(ctx context.Context, id string, parentType string) (string, string, error) {
+	unquotedId, err := strconv.Unquote(id)
+	if err != nil {
+		unquotedId = id
+	}
+
+	parts := strings.Split(unquotedId, "/")
+	switch {
+	case len(parts) == 3 && parts[0] == url.PathEscape(parentType):
+		parentName, parentErr := url.PathUnescape(parts[1])
+		name, nameErr := url.PathUnescape(parts[2])
+		if parentErr == nil && nameErr == nil && parentName != "" && name != "" {
+			return parentName, name, nil
+		}
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		tflog.Debug(ctx, "Parsed legacy ID", map[string]any{"id": unquotedId})
+		return parts[0], parts[1], nil
+	case len(parts) > 2:
+		return "", "", fmt.Errorf("ambiguous ID (%s), expected %s/parent_name/name with the names path escaped, "+
+			"e.g. %s: legacy parent_name/name IDs cannot contain slashes in names",
+			id, parentType, CreateChildId(parentType, "parent", "name/1"))
+	}
+	return "", "", fmt.Errorf("unexpected format of ID (%s), expected %s/parent_name/name", id, parentType)
+}
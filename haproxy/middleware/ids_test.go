@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+)
+
+func TestChildId(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		parentType string
+		wantParent string
+		wantName   string
+		wantErr    bool
+	}{
+		{name: "current", id: "backend/be_web/srv1", parentType: "backend", wantParent: "be_web", wantName: "srv1"},
+		{name: "quoted", id: `"frontend/fe_web/http"`, parentType: "frontend", wantParent: "fe_web", wantName: "http"},
+		{name: "escaped slashes", id: "backend/be%2Fweb/srv%2F1", parentType: "backend", wantParent: "be/web", wantName: "srv/1"},
+		{name: "legacy", id: "be_web/srv1", parentType: "backend", wantParent: "be_web", wantName: "srv1"},
+		{name: "legacy name with slash", id: "be_web/srv/1", parentType: "backend", wantErr: true},
+		{name: "other parent type", id: "frontend/fe_web/http", parentType: "backend", wantErr: true},
+		{name: "invalid escape", id: "backend/be%zz/srv1", parentType: "backend", wantErr: true},
+		{name: "missing name", id: "be_web/", parentType: "backend", wantErr: true},
+		{name: "single part", id: "srv1", parentType: "backend", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parentName, name, err := ParseChildId(context.Background(), tt.id, tt.parentType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if parentName != tt.wantParent || name != tt.wantName {
+				t.Errorf("parsed %q, %q, want %q, %q", parentName, name, tt.wantParent, tt.wantName)
+			}
+		})
+	}
+
+	id := CreateChildId("backend", "be/web", "srv/1")
+	if id != "backend/be%2Fweb/srv%2F1" {
+		t.Errorf("unexpected ID %s", id)
+	}
+	if parentName, name, err := ParseChildId(context.Background(), id, "backend"); err != nil || parentName != "be/web" || name != "srv/1" {
+		t.Errorf("ID does not round trip: %q, %q, %v", parentName, name, err)
+	}
+}
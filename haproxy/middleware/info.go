@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"terraform-provider-haproxy-pf/haproxy/models"
+)
+
+// MinHAProxyVersion is the oldest HAProxy release the provider is tested with.
+const MinHAProxyVersion = "2.0"
+
+func (c *Client) GetInfo(ctx context.Context) (*models.Info, error) {
+	url := c.apiURL("/info", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := models.Info{}
+	if err := c.sendRequest(req, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetProcessInfo returns the runtime information of the HAProxy process.
+func (c *Client) GetProcessInfo(ctx context.Context) (*models.ProcessInfo, error) {
+	url := c.apiURL("/services/haproxy/runtime/info", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// v2 returns one entry per process, v3 a single object
+	var raw json.RawMessage
+	if err := c.sendRequest(req, &raw); err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		var processes []models.ProcessInfo
+		if err := json.Unmarshal(raw, &processes); err != nil {
+			return nil, err
+		}
+		if len(processes) == 0 {
+			return nil, fmt.Errorf("no HAProxy process reported by %s", url)
+		}
+		return &processes[0], nil
+	}
+
+	res := models.ProcessInfo{}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// Negotiate records the Data Plane API version on the client and checks that
+// it matches the selected layout.
+func (c *Client) Negotiate(ctx context.Context) error {
+	info, err := c.GetInfo(ctx)
+	if err != nil {
+		return err
+	}
+	c.dataplaneVersion = normalizeVersion(info.API.Version)
+
+	major, _, _ := parseVersion(c.dataplaneVersion)
+	if major != 0 && "v"+strconv.Itoa(major) != c.apiVersion {
+		return fmt.Errorf("configured %s layout does not match Data Plane API %s", c.apiVersion, info.API.Version)
+	}
+
+	return nil
+}
+
+// DetectHAProxyVersion records the version of the HAProxy process on the
+// client. The version stays unknown when the runtime information cannot be
+// read, e.g. without runtime access, which SupportsHAProxy tolerates.
+func (c *Client) DetectHAProxyVersion(ctx context.Context) error {
+	process, err := c.GetProcessInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot read HAProxy version: %w", err)
+	}
+	c.haproxyVersion = normalizeVersion(process.Info.Version)
+
+	return nil
+}
+
+// DataPlaneVersion returns the Data Plane API version found by Negotiate.
+func (c *Client) DataPlaneVersion() string {
+	return c.dataplaneVersion
+}
+
+// HAProxyVersion returns the HAProxy version found by DetectHAProxyVersion.
+func (c *Client) HAProxyVersion() string {
+	return c.haproxyVersion
+}
+
+// SupportsHAProxy reports whether the HAProxy behind the Data Plane API is at
+// least minimum, e.g. "2.4". It returns true when the version is unknown.
+func (c *Client) SupportsHAProxy(minimum string) bool {
+	if c.haproxyVersion == "" {
+		return true
+	}
+	return compareVersions(c.haproxyVersion, minimum) >= 0
+}
+
+// normalizeVersion turns "v2.6.1 1b7d3e0" or "2.6.5-1ubuntu" into "2.6.1" and "2.6.5".
+func normalizeVersion(version string) string {
+	fields := strings.Fields(version)
+	if len(fields) == 0 {
+		return ""
+	}
+	version = strings.TrimPrefix(fields[0], "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	return version
+}
+
+func parseVersion(version string) (int, int, int) {
+	var parts [3]int
+	for i, part := range strings.SplitN(version, ".", 3) {
+		parts[i], _ = strconv.Atoi(part)
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+// compareVersions returns -1, 0 or 1 when a is lower, equal or greater than b.
+func compareVersions(a string, b string) int {
+	aMajor, aMinor, aPatch := parseVersion(a)
+	bMajor, bMinor, bPatch := parseVersion(b)
+	for _, diff := range []int{aMajor - bMajor, aMinor - bMinor, aPatch - bPatch} {
+		if diff < 0 {
+			return -1
+		}
+		if diff > 0 {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiVersion  string
+		info        string
+		processInfo string
+		wantAPI     string
+		wantHAProxy string
+		wantErr     bool
+		// the HAProxy version cannot be read
+		wantVersionErr bool
+	}{
+		{
+			name:        "v2",
+			apiVersion:  APIVersion2,
+			info:        `{"api":{"build_date":"2022-10-13T12:00:00Z","version":"v2.6.1 1b7d3e0"}}`,
+			processInfo: `[{"info":{"version":"2.6.5-1ubuntu1"}}]`,
+			wantAPI:     "2.6.1",
+			wantHAProxy: "2.6.5",
+		},
+		{
+			name:        "v3",
+			apiVersion:  APIVersion3,
+			info:        `{"api":{"version":"v3.0.1 3b2f1a0"}}`,
+			processInfo: `{"info":{"version":"3.0.2"}}`,
+			wantAPI:     "3.0.1",
+			wantHAProxy: "3.0.2",
+		},
+		{
+			name:           "runtime info denied",
+			apiVersion:     APIVersion2,
+			info:           `{"api":{"version":"v2.6.1 1b7d3e0"}}`,
+			wantAPI:        "2.6.1",
+			wantVersionErr: true,
+		},
+		{
+			name:       "layout mismatch",
+			apiVersion: APIVersion3,
+			info:       `{"api":{"version":"v2.9.0 abcdef0"}}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/" + tt.apiVersion + "/info":
+					_, _ = w.Write([]byte(tt.info))
+				case "/" + tt.apiVersion + "/services/haproxy/runtime/info":
+					if tt.processInfo == "" {
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
+					_, _ = w.Write([]byte(tt.processInfo))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+			_ = client.SetAPIVersion(tt.apiVersion)
+
+			err := client.Negotiate(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if err := client.DetectHAProxyVersion(context.Background()); (err != nil) != tt.wantVersionErr {
+				t.Fatalf("unexpected HAProxy version error: %v", err)
+			}
+			if client.DataPlaneVersion() != tt.wantAPI || client.HAProxyVersion() != tt.wantHAProxy {
+				t.Errorf("got versions %q/%q, want %q/%q", client.DataPlaneVersion(), client.HAProxyVersion(), tt.wantAPI, tt.wantHAProxy)
+			}
+		})
+	}
+}
+
+func TestSupportsHAProxy(t *testing.T) {
+	client := NewClient("admin", "adminpwd", "localhost", true)
+	if !client.SupportsHAProxy("2.8") {
+		t.Error("unknown HAProxy version should be assumed supported")
+	}
+
+	client.haproxyVersion = "2.6.5"
+	for minimum, want := range map[string]bool{"2.0": true, "2.6": true, "2.6.5": true, "2.6.6": false, "2.10": false, "3.0": false} {
+		if got := client.SupportsHAProxy(minimum); got != want {
+			t.Errorf("SupportsHAProxy(%q) = %v, want %v", minimum, got, want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SetConcurrencyLimit bounds the number of requests in flight to the Data
+// Plane API. Zero or less removes the limit.
+func (c *Client) SetConcurrencyLimit(maxConcurrentRequests int) {
+	if maxConcurrentRequests <= 0 {
+		c.requestSlots = nil
+		return
+	}
+	c.requestSlots = make(chan struct{}, maxConcurrentRequests)
+}
+
+// SetRateLimit spaces requests to the Data Plane API so that at most
+// requestsPerSecond are sent every second. Zero or less removes the limit.
+func (c *Client) SetRateLimit(requestsPerSecond float64) {
+	if requestsPerSecond <= 0 {
+		c.rateLimiter = nil
+		return
+	}
+	c.rateLimiter = &rateLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+}
+
+// SerializeTransactions makes WithTransaction wait for the running
+// transaction to be committed before opening the next one, so every
+// transaction starts from the latest configuration version and none fails
+// with a version conflict. It has no effect when batching is enabled, the
+// batch already being the only open transaction.
+func (c *Client) SerializeTransactions(enabled bool) {
+	if !enabled {
+		c.transactionLock = nil
+		return
+	}
+	c.transactionLock = make(chan struct{}, 1)
+}
+
+// acquireRequest waits for the rate and concurrency limits to allow a new
+// request. The returned function releases the request slot.
+func (c *Client) acquireRequest(ctx context.Context) (func(), error) {
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return acquire(ctx, c.requestSlots)
+}
+
+// acquire takes a slot of the semaphore slots, a nil semaphore being unlimited.
+func acquire(ctx context.Context, slots chan struct{}) (func(), error) {
+	if slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// rateLimiter hands out evenly spaced time slots.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until the next free slot, or until ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
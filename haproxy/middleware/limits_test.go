@@ -0,0 +1,110 @@
+package middleware_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"terraform-provider-haproxy-pf/haproxy/dataplanetest"
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+	"terraform-provider-haproxy-pf/haproxy/models"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := middleware.NewClient("admin", "adminpwd", strings.TrimPrefix(server.URL, "http://"), true)
+	client.SetConcurrencyLimit(2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = client.TestApiCall(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight != 2 {
+		t.Errorf("max requests in flight = %d, want 2", maxInFlight)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := middleware.NewClient("admin", "adminpwd", strings.TrimPrefix(server.URL, "http://"), true)
+	client.SetRateLimit(50)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := client.TestApiCall(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first request is immediate, the next ones are 20ms apart
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("5 requests at 50 per second took %s, want at least 80ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client.SetRateLimit(0.1)
+	_ = client.TestApiCall(ctx)
+	cancel()
+	if err := client.TestApiCall(ctx); err != context.Canceled {
+		t.Errorf("expected context.Canceled while waiting, got %v", err)
+	}
+}
+
+func TestSerializeTransactions(t *testing.T) {
+	server := dataplanetest.NewServer(middleware.APIVersion2)
+	defer server.Close()
+
+	client := middleware.NewClient(dataplanetest.Username, dataplanetest.Password, server.Host(), true)
+	client.SerializeTransactions(true)
+
+	// without retries, concurrent transactions on the same version would conflict
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- client.WithTransaction(context.Background(), func(ctx context.Context, transactionId string) error {
+				_, err := client.CreateBackend(ctx, transactionId, models.Backend{Name: fmt.Sprintf("be_%d", i)})
+				return err
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if got := server.Version(); got != 11 {
+		t.Errorf("version = %d, want 11", got)
+	}
+}
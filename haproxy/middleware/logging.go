@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// LogSubsystem is the tflog subsystem of the Data Plane API exchanges. Its
+// level can be set independently with TF_LOG_PROVIDER_HAPROXY_API.
+const LogSubsystem = "haproxy_api"
+
+const redacted = "***"
+
+var (
+	// "password": "...", "secure_password": "...", "secret": "..."
+	secretFieldRegex = regexp.MustCompile(`("[A-Za-z_]*(?:password|secret)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	// PEM private keys embedded in certificate uploads, possibly JSON escaped
+	privateKeyRegex = regexp.MustCompile(`-----BEGIN ([A-Z ]*)PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+)
+
+func logContext(ctx context.Context) context.Context {
+	return tflog.NewSubsystem(ctx, LogSubsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_HAPROXY_API"))
+}
+
+func logRequest(ctx context.Context, req *http.Request) {
+	fields := map[string]interface{}{
+		"http_method":  req.Method,
+		"http_url":     req.URL.Redacted(),
+		"http_headers": redactHeaders(req.Header),
+	}
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Sending HTTP request", fields)
+
+	if req.GetBody == nil {
+		return
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	if err != nil || len(content) == 0 {
+		return
+	}
+	fields["http_request_body"] = RedactBody(string(content))
+	tflog.SubsystemTrace(ctx, LogSubsystem, "HTTP request body", fields)
+}
+
+func logResponse(ctx context.Context, req *http.Request, res *http.Response, body []byte, duration time.Duration) {
+	fields := map[string]interface{}{
+		"http_method":      req.Method,
+		"http_url":         req.URL.Redacted(),
+		"http_status_code": res.StatusCode,
+		"http_duration_ms": duration.Milliseconds(),
+	}
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Received HTTP response", fields)
+
+	if len(body) == 0 {
+		return
+	}
+	fields["http_response_headers"] = redactHeaders(res.Header)
+	fields["http_response_body"] = RedactBody(string(body))
+	tflog.SubsystemTrace(ctx, LogSubsystem, "HTTP response body", fields)
+}
+
+func logTransportError(ctx context.Context, req *http.Request, err error, duration time.Duration) {
+	tflog.SubsystemDebug(ctx, LogSubsystem, "HTTP request failed", map[string]interface{}{
+		"http_method":      req.Method,
+		"http_url":         req.URL.Redacted(),
+		"http_duration_ms": duration.Milliseconds(),
+		"error":            err.Error(),
+	})
+}
+
+// redactHeaders flattens headers for logging, hiding credentials.
+func redactHeaders(headers http.Header) map[string]string {
+	res := make(map[string]string, len(headers))
+	for name, values := range headers {
+		switch http.CanonicalHeaderKey(name) {
+		case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie":
+			res[name] = redacted
+		default:
+			res[name] = strings.Join(values, ", ")
+		}
+	}
+	return res
+}
+
+// RedactBody hides passwords and private keys in a request or response body.
+func RedactBody(body string) string {
+	body = secretFieldRegex.ReplaceAllString(body, `$1"`+redacted+`"`)
+	return privateKeyRegex.ReplaceAllString(body, "-----BEGIN ${1}PRIVATE KEY-----"+redacted+"-----END ${1}PRIVATE KEY-----")
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// objectClient performs the CRUD operations of one kind of configuration
+// object of type T, either a top level section such as backends or the
+// child of a section such as the servers of a backend.
+//
+// A new kind of object only needs a model and a declaration, e.g.
+//
+//	func (c *Client) acls() objectClient[models.ACL] {
+//		return childObjects[models.ACL](c, "frontend", "acls")
+//	}
+type objectClient[T any] struct {
+	client *Client
+	// path segment of the objects, e.g. "backends" or "servers"
+	kind string
+	// type of the parent section, e.g. "backend", empty for sections
+	parentType string
+}
+
+// sectionObjects returns the client of the top level section kind.
+func sectionObjects[T any](c *Client, kind string) objectClient[T] {
+	return objectClient[T]{client: c, kind: kind}
+}
+
+// childObjects returns the client of the objects kind nested in parentType sections.
+func childObjects[T any](c *Client, parentType string, kind string) objectClient[T] {
+	return objectClient[T]{client: c, kind: kind, parentType: parentType}
+}
+
+// on returns o bound to the cluster node ctx applies to, if any.
+func (o objectClient[T]) on(ctx context.Context) objectClient[T] {
+	o.client = o.client.target(ctx)
+	return o
+}
+
+// url returns the URL of the objects of parentName, or of one of them when
+// name is not empty. parentName is ignored for sections.
+func (o objectClient[T]) url(parentName string, name string, query url.Values) string {
+	if o.parentType == "" {
+		return o.client.sectionURL(o.kind, name, query)
+	}
+	return o.client.childURL(o.parentType, parentName, o.kind, name, query)
+}
+
+// list returns the objects of parentName and the configuration version they
+// were read at.
+func (o objectClient[T]) list(ctx context.Context, parentName string) ([]T, int, error) {
+	o = o.on(ctx)
+	res := []T{}
+	version, err := o.client.getData(ctx, o.url(parentName, "", nil), &res)
+	if err != nil {
+		return nil, 0, err
+	}
+	return res, version, nil
+}
+
+func (o objectClient[T]) get(ctx context.Context, parentName string, name string) (*T, error) {
+	if o.client.clustered(ctx) {
+		return o.clusterGet(ctx, parentName, name)
+	}
+
+	o = o.on(ctx)
+	res := new(T)
+	if _, err := o.client.getData(ctx, o.url(parentName, name, nil), res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (o objectClient[T]) create(ctx context.Context, transactionId string, parentName string, object T) (*T, error) {
+	o = o.on(ctx)
+	url := o.url(parentName, "", transactionQuery(transactionId))
+	return o.send(ctx, "POST", url, object)
+}
+
+func (o objectClient[T]) update(ctx context.Context, transactionId string, parentName string, name string, object T) (*T, error) {
+	o = o.on(ctx)
+	url := o.url(parentName, name, transactionQuery(transactionId))
+	return o.send(ctx, "PUT", url, object)
+}
+
+func (o objectClient[T]) delete(ctx context.Context, transactionId string, parentName string, name string) error {
+	o = o.on(ctx)
+	url := o.url(parentName, name, transactionQuery(transactionId))
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	return o.client.sendRequest(req, nil)
+}
+
+// send writes object as the JSON body of a method request to url and decodes
+// the returned object.
+func (o objectClient[T]) send(ctx context.Context, method string, url string, object T) (*T, error) {
+	body, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	res := new(T)
+	if err := o.client.sendRequest(req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestObjectClientNewKind(t *testing.T) {
+	type acl struct {
+		Name      string `json:"acl_name"`
+		Criterion string `json:"criterion"`
+	}
+
+	tests := []struct {
+		apiVersion string
+		wantURI    string
+	}{
+		{APIVersion2, "/v2/services/haproxy/configuration/acls/0?frontend=fe1&parent_name=fe1&parent_type=frontend&transaction_id=tx1"},
+		{APIVersion3, "/v3/services/haproxy/configuration/frontends/fe1/acls/0?transaction_id=tx1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.apiVersion, func(t *testing.T) {
+			var gotMethod, gotURI string
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				gotMethod, gotURI = r.Method, r.URL.RequestURI()
+				_, _ = w.Write([]byte(`{"acl_name":"is_api","criterion":"path_beg"}`))
+			})
+			_ = client.SetAPIVersion(tt.apiVersion)
+
+			acls := childObjects[acl](client, "frontend", "acls")
+			res, err := acls.update(context.Background(), "tx1", "fe1", "0", acl{Name: "is_api", Criterion: "path_beg"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotMethod != http.MethodPut || gotURI != tt.wantURI {
+				t.Errorf("unexpected request %s %s, want PUT %s", gotMethod, gotURI, tt.wantURI)
+			}
+			if res.Name != "is_api" || res.Criterion != "path_beg" {
+				t.Errorf("unexpected result %+v", res)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Supported layouts of the Data Plane API.
+const (
+	// v2 addresses child objects through parent_type/parent_name query
+	// parameters and wraps single objects and lists in {"_version", "data"}.
+	APIVersion2 = "v2"
+	// v3 nests child objects under their parent and returns bare objects.
+	APIVersion3 = "v3"
+)
+
+const configurationPath = "/services/haproxy/configuration/"
+
+// APIVersion returns the layout of the Data Plane API used by the client.
+func (c *Client) APIVersion() string {
+	return c.apiVersion
+}
+
+// SetAPIVersion selects the layout of the Data Plane API, see APIVersion2 and APIVersion3.
+func (c *Client) SetAPIVersion(apiVersion string) error {
+	if apiVersion != APIVersion2 && apiVersion != APIVersion3 {
+		return fmt.Errorf("unsupported Data Plane API version %q, expected %s or %s", apiVersion, APIVersion2, APIVersion3)
+	}
+	c.apiVersion = apiVersion
+	return nil
+}
+
+// DetectAPIVersion probes the info endpoint of every supported layout,
+// newest first, and selects the first one answering.
+func (c *Client) DetectAPIVersion(ctx context.Context) (string, error) {
+	var lastErr error
+	for _, apiVersion := range []string{APIVersion3, APIVersion2} {
+		req, err := http.NewRequestWithContext(ctx, "GET", c.serverURL()+"/"+apiVersion+"/info", nil)
+		if err != nil {
+			return "", err
+		}
+		lastErr = c.sendRequest(req, nil)
+		if lastErr == nil {
+			return apiVersion, c.SetAPIVersion(apiVersion)
+		}
+		if !IsNotFound(lastErr) {
+			break
+		}
+	}
+	return "", fmt.Errorf("cannot detect Data Plane API version: %w", lastErr)
+}
+
+// apiURL returns the URL of path below the versioned API root, followed by
+// the path escaped names and the query parameters.
+func (c *Client) apiURL(path string, query url.Values, names ...string) string {
+	res := c.serverURL() + "/" + c.apiVersion + path
+	for _, name := range names {
+		res += "/" + url.PathEscape(name)
+	}
+	if len(query) > 0 {
+		res += "?" + query.Encode()
+	}
+	return res
+}
+
+// sectionURL returns the URL of a top level configuration section such as
+// backends, or of one of its objects when name is not empty.
+func (c *Client) sectionURL(section string, name string, query url.Values) string {
+	path := configurationPath + section
+	if name == "" {
+		return c.apiURL(path, query)
+	}
+	return c.apiURL(path, query, name)
+}
+
+// childURL returns the URL of the children of type childType, e.g. servers,
+// of the parentType section parentName, or of one of them when name is not empty.
+func (c *Client) childURL(parentType string, parentName string, childType string, name string, query url.Values) string {
+	names := []string{}
+	path := configurationPath + childType
+	if c.apiVersion == APIVersion3 {
+		path = configurationPath + parentType + "s"
+		names = append(names, parentName, childType)
+	} else {
+		query = withValues(query, url.Values{
+			"parent_type": {parentType},
+			"parent_name": {parentName},
+			parentType:    {parentName},
+		})
+	}
+	if name != "" {
+		names = append(names, name)
+	}
+	return c.apiURL(path, query, names...)
+}
+
+// transactionQuery returns the query parameters scoping a change to a transaction.
+func transactionQuery(transactionId string) url.Values {
+	return url.Values{"transaction_id": {transactionId}}
+}
+
+// versionQuery returns the query parameters of a change based on a configuration version.
+func versionQuery(version int) url.Values {
+	return url.Values{"version": {strconv.Itoa(version)}}
+}
+
+// withValues returns a copy of query extended with values.
+func withValues(query url.Values, values url.Values) url.Values {
+	res := url.Values{}
+	for key, value := range query {
+		res[key] = value
+	}
+	for key, value := range values {
+		res[key] = value
+	}
+	return res
+}
+
+// dataEnvelope is the {"_version", "data"} wrapper of v2 GET responses.
+type dataEnvelope struct {
+	Version int         `json:"_version"`
+	Data    interface{} `json:"data"`
+}
+
+// getData sends a GET request to url and decodes the returned object or
+// list into data, unwrapping the v2 envelope. It returns the configuration
+// version of the response, taken from the v2 envelope or from the v3
+// Configuration-Version header, 0 when unknown.
+func (c *Client) getData(ctx context.Context, url string, data interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if c.apiVersion == APIVersion2 {
+		envelope := dataEnvelope{Data: data}
+		if err := c.sendRequest(req, &envelope); err != nil {
+			return 0, err
+		}
+		return envelope.Version, nil
+	}
+
+	header, err := c.sendRequestHeader(req, data)
+	if err != nil {
+		return 0, err
+	}
+	version, _ := strconv.Atoi(header.Get("Configuration-Version"))
+	return version, nil
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"terraform-provider-haproxy-pf/haproxy/models"
+	"testing"
+)
+
+func TestAPIVersionLayouts(t *testing.T) {
+	tests := []struct {
+		apiVersion string
+		wantURI    string
+		body       string
+	}{
+		{
+			apiVersion: APIVersion2,
+			wantURI:    "/v2/services/haproxy/configuration/servers/srv1?backend=be1&parent_name=be1&parent_type=backend",
+			body:       `{"_version":1,"data":{"name":"srv1","address":"127.0.0.1","port":80}}`,
+		},
+		{
+			apiVersion: APIVersion3,
+			wantURI:    "/v3/services/haproxy/configuration/backends/be1/servers/srv1",
+			body:       `{"name":"srv1","address":"127.0.0.1","port":80}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.apiVersion, func(t *testing.T) {
+			var gotURI string
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				gotURI = r.URL.RequestURI()
+				_, _ = w.Write([]byte(tt.body))
+			})
+			if err := client.SetAPIVersion(tt.apiVersion); err != nil {
+				t.Fatal(err)
+			}
+
+			server, err := client.GetServer(context.Background(), "srv1", "be1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gotURI != tt.wantURI {
+				t.Errorf("unexpected request URI:\n got %s\nwant %s", gotURI, tt.wantURI)
+			}
+			if server.Name != "srv1" || server.Address != "127.0.0.1" || server.Port != 80 {
+				t.Errorf("unexpected server: %+v", server)
+			}
+		})
+	}
+}
+
+func TestDetectAPIVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		available string
+		want      string
+		wantErr   bool
+	}{
+		{"v3 server", "/v3/info", APIVersion3, false},
+		{"v2 server", "/v2/info", APIVersion2, false},
+		{"unknown server", "/info", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.available {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(`{"api":{"version":"test"}}`))
+			})
+
+			got, err := client.DetectAPIVersion(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want || (!tt.wantErr && client.APIVersion() != tt.want) {
+				t.Errorf("detected %q (client uses %q), want %q", got, client.APIVersion(), tt.want)
+			}
+		})
+	}
+}
+
+func TestURLEscaping(t *testing.T) {
+	names := []string{
+		"be_web.1:8080",
+		"name with spaces",
+		"a&b=c",
+		"what?",
+		"100%",
+		"slash/name",
+		"#fragment",
+	}
+
+	for _, apiVersion := range []string{APIVersion2, APIVersion3} {
+		for _, name := range names {
+			t.Run(apiVersion+" "+name, func(t *testing.T) {
+				var gotParent, gotName, gotTransaction string
+				client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+					segments := strings.Split(r.URL.EscapedPath(), "/")
+					gotName, _ = url.PathUnescape(segments[len(segments)-1])
+					if apiVersion == APIVersion3 {
+						gotParent, _ = url.PathUnescape(segments[len(segments)-3])
+					} else {
+						gotParent = r.URL.Query().Get("parent_name")
+					}
+					gotTransaction = r.URL.Query().Get("transaction_id")
+					w.WriteHeader(http.StatusAccepted)
+					_, _ = w.Write([]byte(`{}`))
+				})
+				_ = client.SetAPIVersion(apiVersion)
+
+				_, err := client.UpdateServer(context.Background(), "tx&1", models.Server{Name: name}, name)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if gotName != name || gotParent != name {
+					t.Errorf("name did not round-trip: got server %q in parent %q", gotName, gotParent)
+				}
+				if gotTransaction != "tx&1" {
+					t.Errorf("transaction id did not round-trip: got %q", gotTransaction)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"terraform-provider-haproxy-pf/haproxy/models"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Status of a reload returned by GetReload.
+const (
+	ReloadInProgress = "in_progress"
+	ReloadSucceeded  = "succeeded"
+	ReloadFailed     = "failed"
+)
+
+// Reload behaviors of a commit, see SetReloadMode.
+const (
+	// reload asynchronously, after the reload delay of the Data Plane API
+	ReloadModeDefault = "default"
+	// reload immediately, the commit returning once HAProxy reloaded
+	ReloadModeForce = "force"
+	// only write the configuration, a later commit or Reload applies it
+	ReloadModeSkip = "skip"
+)
+
+const reloadsPath = "/services/haproxy/reloads"
+
+type reloadModeContextKey struct{}
+
+// reloadPollInterval is the delay between two checks of a pending reload.
+var reloadPollInterval = time.Second
+
+// ReloadError is returned when HAProxy failed to reload a committed
+// configuration, or when the reload could not be confirmed. The change itself
+// is committed either way.
+type ReloadError struct {
+	ReloadId string
+	// output of the failed reload
+	Response string
+	// error preventing the confirmation of the reload, e.g. a timeout
+	Err error
+}
+
+func (e *ReloadError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("haproxy reload %s not confirmed: %v", e.ReloadId, e.Err)
+	}
+	return fmt.Sprintf("haproxy reload %s failed: %s", e.ReloadId, e.Response)
+}
+
+func (e *ReloadError) Unwrap() error {
+	return e.Err
+}
+
+// IsReloadError reports whether err is a *ReloadError, i.e. the change was
+// committed but HAProxy may not run it.
+func IsReloadError(err error) bool {
+	var reloadErr *ReloadError
+	return errors.As(err, &reloadErr)
+}
+
+func (c *Client) GetReload(ctx context.Context, reloadId string) (*models.Reload, error) {
+	url := c.apiURL(reloadsPath, nil, reloadId)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res := models.Reload{}
+	if err := c.sendRequest(req, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// SetReloadMode selects the reload behavior of commits, see ReloadModeDefault,
+// ReloadModeForce and ReloadModeSkip. WithReloadMode overrides it.
+func (c *Client) SetReloadMode(mode string) error {
+	if err := ValidateReloadMode(mode); err != nil {
+		return err
+	}
+	c.reloadMode = mode
+	return nil
+}
+
+// ValidateReloadMode returns an error if mode is not a known reload mode.
+func ValidateReloadMode(mode string) error {
+	switch mode {
+	case ReloadModeDefault, ReloadModeForce, ReloadModeSkip:
+		return nil
+	}
+	return fmt.Errorf("unsupported reload mode %q, expected %s, %s or %s", mode, ReloadModeDefault, ReloadModeForce, ReloadModeSkip)
+}
+
+// WithReloadMode returns a context committing the transactions of
+// WithTransaction with mode instead of the mode of the client. An empty mode
+// keeps the mode of the client.
+func WithReloadMode(ctx context.Context, mode string) context.Context {
+	if mode == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, reloadModeContextKey{}, mode)
+}
+
+// reloadModeOf returns the reload mode of commits made with ctx.
+func (c *Client) reloadModeOf(ctx context.Context) string {
+	if mode, ok := ctx.Value(reloadModeContextKey{}).(string); ok {
+		return mode
+	}
+	if c.reloadMode == "" {
+		return ReloadModeDefault
+	}
+	return c.reloadMode
+}
+
+// reloadQuery returns the query parameters of a commit with mode.
+func reloadQuery(mode string) url.Values {
+	switch mode {
+	case ReloadModeForce:
+		return url.Values{"force_reload": {"true"}}
+	case ReloadModeSkip:
+		return url.Values{"skip_reload": {"true"}}
+	}
+	return nil
+}
+
+// Reload makes HAProxy reload immediately, applying the changes committed
+// with ReloadModeSkip. It commits an empty transaction with a forced reload.
+func (c *Client) Reload(ctx context.Context) error {
+	return c.WithTransaction(WithReloadMode(ctx, ReloadModeForce), func(ctx context.Context, transactionId string) error {
+		return nil
+	})
+}
+
+// WaitForReloads makes WithTransaction wait up to timeout for the reload
+// triggered by a commit, and fail if HAProxy could not reload. Zero disables
+// waiting.
+func (c *Client) WaitForReloads(timeout time.Duration) {
+	c.reloadTimeout = timeout
+}
+
+// WaitForReload polls a reload until it is no longer in progress. It returns
+// a *ReloadError if the reload failed.
+func (c *Client) WaitForReload(ctx context.Context, reloadId string) error {
+	for {
+		reload, err := c.GetReload(ctx, reloadId)
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("timed out waiting for haproxy reload %s: %w", reloadId, ctx.Err())
+		}
+		if err != nil {
+			// not wrapped: the change is committed, it must not be retried
+			return fmt.Errorf("cannot get status of haproxy reload %s: %v", reloadId, err)
+		}
+
+		switch reload.Status {
+		case ReloadSucceeded:
+			tflog.Debug(ctx, "Haproxy reload succeeded", map[string]any{"reload_id": reloadId})
+			return nil
+		case ReloadFailed:
+			return &ReloadError{ReloadId: reloadId, Response: reload.Response}
+		}
+
+		timer := time.NewTimer(reloadPollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("timed out waiting for haproxy reload %s: %w", reloadId, ctx.Err())
+		}
+	}
+}
+
+// waitForReload waits for reloadId when enabled by WaitForReloads.
+func (c *Client) waitForReload(ctx context.Context, reloadId string) error {
+	if c.reloadTimeout == 0 || reloadId == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.reloadTimeout)
+	defer cancel()
+	err := c.WaitForReload(ctx, reloadId)
+	if err != nil && !IsReloadError(err) {
+		// the change is committed, whatever happened to the reload
+		return &ReloadError{ReloadId: reloadId, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"terraform-provider-haproxy-pf/haproxy/dataplanetest"
+	"terraform-provider-haproxy-pf/haproxy/models"
+	"testing"
+	"time"
+)
+
+func TestWaitForReloads(t *testing.T) {
+	defer func(interval time.Duration) { reloadPollInterval = interval }(reloadPollInterval)
+	reloadPollInterval = time.Millisecond
+
+	tests := []struct {
+		name          string
+		wait          bool
+		failure       string
+		wantReloadErr bool
+		wantPolls     int
+	}{
+		{name: "not waiting", failure: "[ALERT] parsing error"},
+		{name: "reload succeeded", wait: true, wantPolls: 2},
+		{name: "reload failed", wait: true, failure: "[ALERT] parsing error", wantReloadErr: true, wantPolls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := dataplanetest.NewServer(APIVersion2)
+			defer server.Close()
+			server.FailReloads(tt.failure)
+
+			client := NewClient(dataplanetest.Username, dataplanetest.Password, server.Host(), true)
+			if tt.wait {
+				client.WaitForReloads(time.Second)
+			}
+
+			err := client.WithTransaction(context.Background(), func(ctx context.Context, transactionId string) error {
+				_, err := client.CreateBackend(ctx, transactionId, models.Backend{Name: "be_web"})
+				return err
+			})
+
+			var reloadErr *ReloadError
+			if errors.As(err, &reloadErr) != tt.wantReloadErr || (err != nil && !tt.wantReloadErr) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantReloadErr && (reloadErr.ReloadId != "reload-1" || reloadErr.Response != tt.failure || IsRetryable(err)) {
+				t.Errorf("unexpected reload error %#v", reloadErr)
+			}
+
+			polls := 0
+			for _, request := range server.Requests() {
+				if request == "GET /v2/services/haproxy/reloads/reload-1" {
+					polls++
+				}
+			}
+			if polls != tt.wantPolls {
+				t.Errorf("reload polled %d times, want %d", polls, tt.wantPolls)
+			}
+		})
+	}
+}
+
+func TestWaitForReloadTimeout(t *testing.T) {
+	defer func(interval time.Duration) { reloadPollInterval = interval }(reloadPollInterval)
+	reloadPollInterval = time.Millisecond
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"reload-1","status":"in_progress"}`))
+	})
+	client.WaitForReloads(20 * time.Millisecond)
+
+	err := client.waitForReload(context.Background(), "reload-1")
+	if !IsReloadError(err) || IsRetryable(err) || !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "reload-1") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestReloadModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		clientMode  string
+		modes       []string
+		batch       bool
+		wantReloads int
+	}{
+		{name: "default", modes: []string{""}, wantReloads: 1},
+		{name: "client skip", clientMode: ReloadModeSkip, modes: []string{""}, wantReloads: 0},
+		{name: "client force", clientMode: ReloadModeForce, modes: []string{""}, wantReloads: 1},
+		{name: "context overrides client", clientMode: ReloadModeForce, modes: []string{ReloadModeSkip}, wantReloads: 0},
+		{name: "batch of skips", batch: true, modes: []string{ReloadModeSkip, ReloadModeSkip}, wantReloads: 0},
+		{name: "batch reloads for any operation", batch: true, modes: []string{ReloadModeSkip, ReloadModeForce}, wantReloads: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := dataplanetest.NewServer(APIVersion2)
+			defer server.Close()
+
+			client := NewClient(dataplanetest.Username, dataplanetest.Password, server.Host(), true)
+			if tt.clientMode != "" {
+				if err := client.SetReloadMode(tt.clientMode); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.batch {
+				client.EnableBatching(50 * time.Millisecond)
+			}
+
+			errs := make(chan error, len(tt.modes))
+			for i, mode := range tt.modes {
+				go func(name string, mode string) {
+					errs <- client.WithTransaction(WithReloadMode(context.Background(), mode), func(ctx context.Context, transactionId string) error {
+						_, err := client.CreateBackend(ctx, transactionId, models.Backend{Name: name})
+						return err
+					})
+				}(fmt.Sprintf("be_%d", i), mode)
+			}
+			for range tt.modes {
+				if err := <-errs; err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			if reloads := len(server.Reloads()); reloads != tt.wantReloads {
+				t.Errorf("got %d reloads, want %d", reloads, tt.wantReloads)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	server := dataplanetest.NewServer(APIVersion2)
+	defer server.Close()
+	client := NewClient(dataplanetest.Username, dataplanetest.Password, server.Host(), true)
+
+	if err := client.Reload(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reloads := server.Reloads(); len(reloads) != 1 {
+		t.Errorf("expected a single reload, got %v", reloads)
+	}
+
+	server.FailReloads("[ALERT] parsing error")
+	if err := client.Reload(context.Background()); !IsValidation(err) {
+		t.Errorf("expected the failed reload to be rejected, got %v", err)
+	}
+
+	if err := client.SetReloadMode("later"); err == nil {
+		t.Error("expected an unsupported reload mode error")
+	}
+}
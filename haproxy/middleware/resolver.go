@@ -1,23 +1,25 @@
 package middleware
 
 import (
-	"fmt"
-	"net/http"
+	"context"
 	"terraform-provider-haproxy-pf/haproxy/models"
 )
 
+func (c *Client) resolvers() objectClient[models.Resolver] {
+	return sectionObjects[models.Resolver](c, "resolvers")
+}
+
 // return all resolvers
-func (c *Client) GetResolvers() (*models.GetResolvers, error) {
-	url := fmt.Sprintf("%s/services/haproxy/configuration/resolvers", c.base_url)
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) GetResolvers(ctx context.Context) (*models.GetResolvers, error) {
+	data, version, err := c.resolvers().list(ctx, "")
 	if err != nil {
 		return nil, err
 	}
 
-	res := models.GetResolvers{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
+	return &models.GetResolvers{Version: version, Data: data}, nil
+}
 
-	return &res, nil
+// return single resolver
+func (c *Client) GetResolver(ctx context.Context, resolverName string) (*models.Resolver, error) {
+	return c.resolvers().get(ctx, "", resolverName)
 }
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/avast/retry-go/v4"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// RetryPolicy controls how Client.Retry repeats failed operations.
+type RetryPolicy struct {
+	// total number of attempts, including the first one
+	Attempts uint
+	// base delay, doubled after every failed attempt
+	Delay time.Duration
+	// upper bound for the delay between two attempts, 0 means no bound
+	MaxBackoff time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts: 10,
+		Delay:    100 * time.Millisecond,
+	}
+}
+
+// IsRetryable reports whether an operation failing with err may succeed when
+// attempted again: configuration version conflicts, server errors, timeouts
+// and refused or reset connections are retried. Validation errors, the other
+// conflicts, such as objects that already exist, and the other transport
+// errors, such as certificate verification failures or invalid URLs, are not.
+func IsRetryable(err error) bool {
+	// a failed reload follows a commit which must not be repeated
+	if IsReloadError(err) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if IsVersionConflict(err) || IsServerError(err) {
+		return true
+	}
+
+	if isCertificateError(err) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isCertificateError reports whether err is a failed verification of the
+// Data Plane API certificate, which does not change between attempts.
+func isCertificateError(err error) bool {
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	return errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr)
+}
+
+// Retry runs fn until it succeeds, fails with an error that is not
+// retryable, the policy runs out of attempts or ctx is done.
+func (c *Client) Retry(ctx context.Context, fn func() error) error {
+	return retry.Do(
+		fn,
+		retry.Context(ctx),
+		retry.Attempts(c.RetryPolicy.Attempts),
+		retry.Delay(c.RetryPolicy.Delay),
+		retry.MaxDelay(c.RetryPolicy.MaxBackoff),
+		retry.RetryIf(IsRetryable),
+		retry.OnRetry(func(n uint, err error) {
+			tflog.Debug(ctx, "Retrying Haproxy operation", map[string]any{
+				"attempt": n + 1,
+				"error":   err.Error(),
+			})
+		}),
+	)
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// transportError wraps errno like the errors of a failed HTTP request.
+func transportError(errno syscall.Errno) error {
+	return &url.Error{Op: "Get", URL: "http://localhost", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", errno)}}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"version conflict", &APIError{StatusCode: http.StatusConflict, Message: "version mismatch, have 3, given 2"}, true},
+		{"configuration version conflict", &APIError{StatusCode: http.StatusConflict, Message: "80: version in configuration file is 3, given version is 2"}, true},
+		{"outdated transaction", &APIError{StatusCode: http.StatusConflict, Message: "transaction tx-1 is outdated, version 3 is committed"}, true},
+		{"object already exists", &APIError{StatusCode: http.StatusConflict, Message: "41: backend be_web already exists"}, false},
+		{"server error", &APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"validation error", &APIError{StatusCode: http.StatusBadRequest}, false},
+		{"not found", &APIError{StatusCode: http.StatusNotFound}, false},
+		{"connection refused", transportError(syscall.ECONNREFUSED), true},
+		{"connection reset", transportError(syscall.ECONNRESET), true},
+		{"timeout", &url.Error{Op: "Get", URL: "http://localhost", Err: timeoutError{}}, true},
+		{"unknown certificate authority", &url.Error{Op: "Get", URL: "https://localhost", Err: x509.UnknownAuthorityError{}}, false},
+		{"certificate hostname mismatch", &url.Error{Op: "Get", URL: "https://localhost", Err: x509.HostnameError{Host: "localhost"}}, false},
+		{"invalid url", &url.Error{Op: "parse", URL: "http://%zz", Err: url.EscapeError("%zz")}, false},
+		{"cancelled", &url.Error{Op: "Get", URL: "http://localhost", Err: context.Canceled}, false},
+		{"decoding error", errors.New("unexpected EOF"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	client := NewClient("admin", "adminpwd", "localhost", true)
+	client.RetryPolicy = RetryPolicy{Attempts: 3, Delay: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	attempts := 0
+	err := client.Retry(context.Background(), func() error {
+		attempts++
+		return &APIError{StatusCode: http.StatusConflict, Message: "version mismatch, have 3, given 2"}
+	})
+	if err == nil || attempts != 3 {
+		t.Errorf("expected 3 attempts for a version conflict, got %d (err: %v)", attempts, err)
+	}
+
+	attempts = 0
+	err = client.Retry(context.Background(), func() error {
+		attempts++
+		return &APIError{StatusCode: http.StatusConflict, Message: "object be_web already exists"}
+	})
+	if !IsConflict(err) || attempts != 1 {
+		t.Errorf("expected a single attempt for an existing object, got %d (err: %v)", attempts, err)
+	}
+
+	attempts = 0
+	err = client.Retry(context.Background(), func() error {
+		attempts++
+		return &APIError{StatusCode: http.StatusBadRequest}
+	})
+	if !IsValidation(err) || attempts != 1 {
+		t.Errorf("expected a single attempt for a validation error, got %d (err: %v)", attempts, err)
+	}
+}
@@ -1,91 +1,37 @@
 package middleware
 
 import (
-	"fmt"
-	"bytes"
-	"encoding/json"
-	"net/http"
+	"context"
 	"terraform-provider-haproxy-pf/haproxy/models"
 )
 
-// return all server_templates
-func (c *Client) GetServerTemplates(parentName string) (*models.GetServerTemplates, error) {
-	url := fmt.Sprintf("%s/services/haproxy/configuration/server_templates/?backend=%s", c.base_url, parentName)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	res := models.GetServerTemplates{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+func (c *Client) serverTemplates() objectClient[models.ServerTemplate] {
+	return childObjects[models.ServerTemplate](c, "backend", "server_templates")
 }
 
-// return single server_templates
-func (c *Client) GetServerTemplate(serverTemplateName string, parentName string) (*models.ServerTemplate, error) {
-	url := fmt.Sprintf("%s/services/haproxy/configuration/server_templates/%s?backend=%s", c.base_url, serverTemplateName, parentName)
-	req, err := http.NewRequest("GET", url, nil)
+// return all server templates of a backend
+func (c *Client) GetServerTemplates(ctx context.Context, parentName string) (*models.GetServerTemplates, error) {
+	data, version, err := c.serverTemplates().list(ctx, parentName)
 	if err != nil {
 		return nil, err
 	}
 
-	res := models.GetServerTemplate{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res.Data, nil
+	return &models.GetServerTemplates{Version: version, Data: data}, nil
 }
 
-func (c *Client) CreateServerTemplate(transactionId string, serverTemplate models.ServerTemplate, parentName string) (*models.ServerTemplate, error) {
-	url := fmt.Sprintf("%s/services/haproxy/configuration/server_templates/?transaction_id=%s&backend=%s", c.base_url, transactionId, parentName)
-	bodyStr, _ := json.Marshal(serverTemplate)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(bodyStr))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	res := models.ServerTemplate{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+// return single server template
+func (c *Client) GetServerTemplate(ctx context.Context, serverTemplateName string, parentName string) (*models.ServerTemplate, error) {
+	return c.serverTemplates().get(ctx, parentName, serverTemplateName)
 }
 
-func (c *Client) UpdateServerTemplate(transactionId string, serverTemplate models.ServerTemplate, parentName string) (*models.ServerTemplate, error) {
-	url := fmt.Sprintf("%s/services/haproxy/configuration/server_templates/%s?transaction_id=%s&backend=%s", c.base_url, serverTemplate.Prefix, transactionId, parentName)
-	bodyStr, _ := json.Marshal(serverTemplate)
-	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(bodyStr))
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	res := models.ServerTemplate{}
-	if err := c.sendRequest(req, &res); err != nil {
-		return nil, err
-	}
-
-	return &res, nil
+func (c *Client) CreateServerTemplate(ctx context.Context, transactionId string, serverTemplate models.ServerTemplate, parentName string) (*models.ServerTemplate, error) {
+	return c.serverTemplates().create(ctx, transactionId, parentName, serverTemplate)
 }
 
-func (c *Client) DeleteServerTemplate(transactionId string, serverTemplateName string, parentName string) error {
-	url := fmt.Sprintf("%s/services/haproxy/configuration/server_templates/%s?transaction_id=%s&parent_type=backend&parent_name=%s&backend=%s", c.base_url, serverTemplateName, transactionId, parentName, parentName)
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return err
-	}
-
-	if err := c.sendRequest(req, nil); err != nil {
-		return err
-	}
+func (c *Client) UpdateServerTemplate(ctx context.Context, transactionId string, serverTemplate models.ServerTemplate, parentName string) (*models.ServerTemplate, error) {
+	return c.serverTemplates().update(ctx, transactionId, parentName, serverTemplate.Prefix, serverTemplate)
+}
 
-	return nil
+func (c *Client) DeleteServerTemplate(ctx context.Context, transactionId string, serverTemplateName string, parentName string) error {
+	return c.serverTemplates().delete(ctx, transactionId, parentName, serverTemplateName)
 }
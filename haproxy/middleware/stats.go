@@ -1,10 +1,13 @@
 package middleware
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
-func (c *Client) TestApiCall() error {
-	url := c.base_url + "/services/haproxy/stats/native"
-	req, err := http.NewRequest("GET", url, nil)
+func (c *Client) TestApiCall(ctx context.Context) error {
+	url := c.apiURL("/services/haproxy/stats/native", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TLSOptions configures the TLS connection to the Data Plane API.
+type TLSOptions struct {
+	// path of a PEM encoded CA bundle used instead of the system trust store
+	CAFile string
+	// PEM encoded CA bundle, appended to CAFile
+	CAPEM string
+	// client certificate and key for mutual TLS, either PEM content or a file path
+	ClientCert string
+	ClientKey  string
+	// overrides the server name used for certificate verification and SNI
+	ServerName string
+	// disables verification of the server certificate
+	InsecureSkipVerify bool
+}
+
+// Config builds the tls.Config described by the options.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" || o.CAPEM != "" {
+		pool := x509.NewCertPool()
+		if o.CAFile != "" {
+			ca, err := os.ReadFile(o.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(ca) {
+				return nil, fmt.Errorf("no certificate found in CA file %s", o.CAFile)
+			}
+		}
+		if o.CAPEM != "" && !pool.AppendCertsFromPEM([]byte(o.CAPEM)) {
+			return nil, errors.New("no certificate found in CA PEM")
+		}
+		config.RootCAs = pool
+	}
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		if o.ClientCert == "" || o.ClientKey == "" {
+			return nil, errors.New("client certificate and client key must be set together")
+		}
+		cert, err := loadPEM(o.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read client certificate: %w", err)
+		}
+		key, err := loadPEM(o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read client key: %w", err)
+		}
+		keyPair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{keyPair}
+	}
+
+	return config, nil
+}
+
+// SetTLSConfig replaces the TLS configuration used to reach the Data Plane API.
+func (c *Client) SetTLSConfig(config *tls.Config) {
+	c.transport().TLSClientConfig = config
+}
+
+func (c *Client) transport() *http.Transport {
+	transport, ok := c.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		c.HTTPClient.Transport = transport
+	}
+	return transport
+}
+
+// loadPEM returns value itself when it holds PEM data, otherwise the content
+// of the file it points to.
+func loadPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTLSOptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	tests := []struct {
+		name    string
+		options TLSOptions
+		wantErr bool
+	}{
+		{"system trust store", TLSOptions{}, true},
+		{"pinned CA", TLSOptions{CAPEM: caPEM}, false},
+		{"pinned CA and server name", TLSOptions{CAPEM: caPEM, ServerName: "example.com"}, false},
+		{"pinned CA and wrong server name", TLSOptions{CAPEM: caPEM, ServerName: "haproxy.internal"}, true},
+		{"skip verify", TLSOptions{InsecureSkipVerify: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := tt.options.Config()
+			if err != nil {
+				t.Fatalf("unexpected configuration error: %v", err)
+			}
+			client := NewClient("admin", "adminpwd", host, false)
+			client.SetTLSConfig(config)
+
+			err = client.TestApiCall(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected result: %v", err)
+			}
+		})
+	}
+}
+
+func TestTLSOptionsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TLSOptions
+	}{
+		{"invalid CA", TLSOptions{CAPEM: "-----BEGIN CERTIFICATE-----\nnot a certificate\n-----END CERTIFICATE-----"}},
+		{"missing CA file", TLSOptions{CAFile: "/nonexistent/ca.pem"}},
+		{"certificate without key", TLSOptions{ClientCert: "-----BEGIN CERTIFICATE-----"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.options.Config(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -1,14 +1,30 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
-	"strconv"
 	"terraform-provider-haproxy-pf/haproxy/models"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
-func (c *Client) CreateTransaction(version int) (*models.Transaction, error) {
-	url := c.base_url + "/services/haproxy/transactions?version=" + strconv.Itoa(version)
-	req, err := http.NewRequest("POST", url, nil)
+// discardTimeout bounds the cleanup of a failed transaction when the
+// operation context has already been cancelled.
+const discardTimeout = 30 * time.Second
+
+const transactionsPath = "/services/haproxy/transactions"
+
+// ErrNothingToCommit is returned by the functions run with WithTransaction
+// when they found nothing to change, e.g. deleting an object already
+// deleted. The transaction is discarded instead of committed, so HAProxy is
+// not reloaded.
+var ErrNothingToCommit = errors.New("nothing to commit")
+
+func (c *Client) CreateTransaction(ctx context.Context, version int) (*models.Transaction, error) {
+	url := c.apiURL(transactionsPath, versionQuery(version))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -21,17 +37,109 @@ func (c *Client) CreateTransaction(version int) (*models.Transaction, error) {
 	return &res, nil
 }
 
-func (c *Client) CommitTransaction(transactionId string) (*models.Transaction, error) {
-	url := c.base_url + "/services/haproxy/transactions/" + transactionId
-	req, err := http.NewRequest("PUT", url, nil)
+// CommitTransaction commits a transaction, reloading HAProxy according to
+// the reload mode of ctx, see WithReloadMode.
+func (c *Client) CommitTransaction(ctx context.Context, transactionId string) (*models.Transaction, error) {
+	url := c.apiURL(transactionsPath, reloadQuery(c.reloadModeOf(ctx)), transactionId)
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	res := models.Transaction{}
-	if err := c.sendRequest(req, &res); err != nil {
+	header, err := c.sendRequestHeader(req, &res)
+	if err != nil {
 		return nil, err
 	}
 
+	res.ReloadId = header.Get("Reload-ID")
+	if res.ReloadId != "" {
+		tflog.Debug(ctx, "Haproxy reload scheduled", map[string]any{
+			"transaction_id": transactionId,
+			"reload_id":      res.ReloadId,
+		})
+	}
+
 	return &res, nil
 }
+
+func (c *Client) DeleteTransaction(ctx context.Context, transactionId string) error {
+	url := c.apiURL(transactionsPath, nil, transactionId)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.sendRequest(req, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// WithTransaction opens a transaction on the current configuration version,
+// runs fn inside it and commits it. If fn or the commit fails the transaction
+// is deleted so it is not left open on the Data Plane API, as it is when fn
+// returns ErrNothingToCommit.
+// When batching is enabled the transaction is shared with concurrent calls
+// and WithTransaction returns once the shared transaction is committed.
+// In cluster mode fn runs once per node, see SetCluster.
+// With WaitForReloads, it also waits for the reload triggered by the commit.
+func (c *Client) WithTransaction(ctx context.Context, fn func(ctx context.Context, transactionId string) error) error {
+	if c.clustered(ctx) {
+		return c.clusterTransaction(ctx, fn)
+	}
+
+	if c.batcher != nil {
+		return c.batcher.run(ctx, fn)
+	}
+
+	release, err := acquire(ctx, c.transactionLock)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	configuration, err := c.GetConfiguration(ctx)
+	if err != nil {
+		return err
+	}
+	transaction, err := c.CreateTransaction(ctx, configuration.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(ctx, transaction.Id); err != nil {
+		c.discardTransaction(ctx, transaction.Id)
+		if errors.Is(err, ErrNothingToCommit) {
+			return nil
+		}
+		return err
+	}
+
+	committed, err := c.CommitTransaction(ctx, transaction.Id)
+	if err != nil {
+		c.discardTransaction(ctx, transaction.Id)
+		return err
+	}
+
+	return c.waitForReload(ctx, committed.ReloadId)
+}
+
+// discardTransaction deletes a transaction, logging instead of returning
+// errors since it always runs after another failure.
+func (c *Client) discardTransaction(ctx context.Context, transactionId string) {
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), discardTimeout)
+		defer cancel()
+	}
+
+	err := c.DeleteTransaction(ctx, transactionId)
+	if err != nil && !IsNotFound(err) {
+		tflog.Warn(ctx, "Could not delete Haproxy transaction", map[string]any{
+			"transaction_id": transactionId,
+			"error":          err.Error(),
+		})
+	}
+}
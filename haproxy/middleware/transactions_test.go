@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestWithTransaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		opStatus  int
+		wantCalls []string
+		wantErr   bool
+	}{
+		{
+			name:     "commit on success",
+			opStatus: http.StatusNoContent,
+			wantCalls: []string{
+				"GET /v2/services/haproxy/configuration/raw",
+				"POST /v2/services/haproxy/transactions",
+				"DELETE /v2/services/haproxy/configuration/backends/be_test",
+				"PUT /v2/services/haproxy/transactions/tx-1",
+			},
+		},
+		{
+			name:     "discard on failure",
+			opStatus: http.StatusBadRequest,
+			wantCalls: []string{
+				"GET /v2/services/haproxy/configuration/raw",
+				"POST /v2/services/haproxy/transactions",
+				"DELETE /v2/services/haproxy/configuration/backends/be_test",
+				"DELETE /v2/services/haproxy/transactions/tx-1",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "discard when nothing to commit",
+			opStatus: http.StatusNotFound,
+			wantCalls: []string{
+				"GET /v2/services/haproxy/configuration/raw",
+				"POST /v2/services/haproxy/transactions",
+				"DELETE /v2/services/haproxy/configuration/backends/be_test",
+				"DELETE /v2/services/haproxy/transactions/tx-1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var calls []string
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				calls = append(calls, r.Method+" "+r.URL.Path)
+				mu.Unlock()
+
+				switch {
+				case r.URL.Path == "/v2/services/haproxy/configuration/raw":
+					_, _ = w.Write([]byte(`{"_version":3,"data":""}`))
+				case r.Method == http.MethodPost && r.URL.Path == "/v2/services/haproxy/transactions":
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"_version":3,"id":"tx-1","status":"in_progress"}`))
+				case r.URL.Path == "/v2/services/haproxy/transactions/tx-1":
+					_, _ = w.Write([]byte(`{"_version":3,"id":"tx-1","status":"success"}`))
+				default:
+					w.WriteHeader(tt.opStatus)
+				}
+			})
+
+			err := client.WithTransaction(context.Background(), func(ctx context.Context, transactionId string) error {
+				err := client.DeleteBackend(ctx, transactionId, "be_test")
+				if IsNotFound(err) {
+					return ErrNothingToCommit
+				}
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("unexpected calls:\n got %v\nwant %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
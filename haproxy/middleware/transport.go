@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// SetTimeout bounds every request to the Data Plane API, reading the
+// response included. Zero disables the timeout.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.HTTPClient.Timeout = timeout
+}
+
+// SetProxy sends the requests through the HTTP proxy proxyURL, except those
+// to the hosts matched by noProxy, a comma separated list in the NO_PROXY
+// format. Empty values keep the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables.
+func (c *Client) SetProxy(proxyURL string, noProxy string) error {
+	config := httpproxy.FromEnvironment()
+	if proxyURL != "" {
+		if _, err := url.Parse(proxyURL); err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		config.HTTPProxy = proxyURL
+		config.HTTPSProxy = proxyURL
+	}
+	if noProxy != "" {
+		config.NoProxy = noProxy
+	}
+
+	proxy := config.ProxyFunc()
+	c.transport().Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+	return nil
+}
+
+// SetUserAgent sets the User-Agent header of every request.
+func (c *Client) SetUserAgent(userAgent string) {
+	c.userAgent = userAgent
+}
+
+// SetHeaders adds headers to every request, e.g. for a gateway in front of
+// the Data Plane API. They can override the User-Agent but not the
+// authentication of the client.
+func (c *Client) SetHeaders(headers map[string]string) {
+	c.headers = headers
+}
+
+// setHeaders sets the headers common to every request on req.
+func (c *Client) setHeaders(req *http.Request) {
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Authorization", "Basic "+basicAuth(c.username, c.password))
+}
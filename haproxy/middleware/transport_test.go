@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestHeaders(t *testing.T) {
+	var got http.Header
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`[]`))
+	})
+	client.SetUserAgent("terraform-provider-haproxy-pf/1.2.3")
+	client.SetHeaders(map[string]string{
+		"X-Gateway-Key": "secret",
+		"Authorization": "Bearer ignored",
+	})
+
+	if err := client.TestApiCall(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Get("User-Agent") != "terraform-provider-haproxy-pf/1.2.3" {
+		t.Errorf("unexpected User-Agent %q", got.Get("User-Agent"))
+	}
+	if got.Get("X-Gateway-Key") != "secret" {
+		t.Errorf("custom header not sent: %v", got)
+	}
+	if got.Get("Authorization") != "Basic "+basicAuth("admin", "adminpwd") {
+		t.Errorf("custom headers must not override the authentication, got %q", got.Get("Authorization"))
+	}
+}
+
+func TestSetTimeout(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	})
+	client.SetTimeout(10 * time.Millisecond)
+
+	err := client.TestApiCall(context.Background())
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestSetProxy(t *testing.T) {
+	client := NewClient("admin", "adminpwd", "lb.example.com:5555", false)
+	if err := client.SetProxy("http://proxy.example.com:3128", "10.0.0.0/8,.internal"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"https://lb.example.com:5555/v2/info", "http://proxy.example.com:3128"},
+		{"https://lb.internal:5555/v2/info", ""},
+		{"https://10.1.2.3:5555/v2/info", ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.target, nil)
+		proxy, err := client.transport().Proxy(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := urlString(proxy); got != tt.want {
+			t.Errorf("proxy of %s is %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func urlString(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"terraform-provider-haproxy-pf/haproxy/models"
+)
+
+// SetValidateOnPlan enables the validation of planned changes, see ValidateChange.
+func (c *Client) SetValidateOnPlan(enabled bool) {
+	c.validateOnPlan = enabled
+}
+
+// ValidatesOnPlan reports whether planned changes should be validated with
+// ValidateChange.
+func (c *Client) ValidatesOnPlan() bool {
+	return c.validateOnPlan
+}
+
+// SetStrictReferences makes references to missing sections, such as the
+// default backend of a frontend, errors of the plan rather than warnings.
+func (c *Client) SetStrictReferences(enabled bool) {
+	c.strictReferences = enabled
+}
+
+// StrictReferences reports whether references to missing sections fail the plan.
+func (c *Client) StrictReferences() bool {
+	return c.strictReferences
+}
+
+// GetTransactionConfiguration returns the raw configuration staged in a transaction.
+func (c *Client) GetTransactionConfiguration(ctx context.Context, transactionId string) (string, error) {
+	query := url.Values{"transaction_id": {transactionId}}
+	url := c.apiURL(configurationPath+"raw", query)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if c.apiVersion == APIVersion3 {
+		// v3 returns the raw configuration as plain text
+		req.Header.Set("Accept", "text/plain")
+		var raw []byte
+		if err := c.sendRequest(req, &raw); err != nil {
+			return "", err
+		}
+		return string(raw), nil
+	}
+
+	res := models.Configuration{}
+	if err := c.sendRequest(req, &res); err != nil {
+		return "", err
+	}
+
+	return res.Data, nil
+}
+
+// ValidateConfiguration asks HAProxy to check a raw configuration without
+// applying it. An invalid configuration is returned as an APIError matched by
+// IsValidation, with the HAProxy output as message.
+func (c *Client) ValidateConfiguration(ctx context.Context, raw string) error {
+	query := url.Values{"only_validate": {"true"}, "skip_version": {"true"}}
+	url := c.apiURL(configurationPath+"raw", query)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	return c.sendRequest(req, nil)
+}
+
+// ValidateChange stages the changes of fn in a transaction that is never
+// committed and validates the resulting configuration with
+// ValidateConfiguration. The transaction is always deleted. It bypasses
+// batching and, in cluster mode, only validates on the first node.
+func (c *Client) ValidateChange(ctx context.Context, fn func(ctx context.Context, transactionId string) error) error {
+	configuration, err := c.GetConfiguration(ctx)
+	if err != nil {
+		return err
+	}
+	transaction, err := c.CreateTransaction(ctx, configuration.Version)
+	if err != nil {
+		return err
+	}
+	defer c.discardTransaction(ctx, transaction.Id)
+
+	if err := fn(ctx, transaction.Id); err != nil {
+		return err
+	}
+
+	raw, err := c.GetTransactionConfiguration(ctx, transaction.Id)
+	if err != nil {
+		return err
+	}
+
+	return c.ValidateConfiguration(ctx, raw)
+}
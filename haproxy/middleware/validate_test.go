@@ -0,0 +1,75 @@
+package middleware_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-provider-haproxy-pf/haproxy/dataplanetest"
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+	"terraform-provider-haproxy-pf/haproxy/models"
+)
+
+func TestValidateChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		stage   func(ctx context.Context, client *middleware.Client, transactionId string) error
+		wantErr func(err error) bool
+	}{
+		{
+			name: "valid",
+			stage: func(ctx context.Context, client *middleware.Client, transactionId string) error {
+				_, err := client.CreateFrontend(ctx, transactionId, models.Frontend{Name: "fe_web", DefaultBackend: "be_web"})
+				return err
+			},
+		},
+		{
+			name: "invalid configuration",
+			stage: func(ctx context.Context, client *middleware.Client, transactionId string) error {
+				_, err := client.CreateFrontend(ctx, transactionId, models.Frontend{Name: "fe_web", DefaultBackend: "be_missing"})
+				return err
+			},
+			wantErr: func(err error) bool {
+				return middleware.IsValidation(err) && strings.Contains(err.Error(), "be_missing")
+			},
+		},
+		{
+			name: "staging failure",
+			stage: func(ctx context.Context, client *middleware.Client, transactionId string) error {
+				_, err := client.CreateServer(ctx, transactionId, models.Server{Name: "srv1"}, "be_missing")
+				return err
+			},
+			wantErr: middleware.IsNotFound,
+		},
+	}
+
+	for _, apiVersion := range []string{middleware.APIVersion2, middleware.APIVersion3} {
+		for _, tt := range tests {
+			t.Run(apiVersion+" "+tt.name, func(t *testing.T) {
+				server := dataplanetest.NewServer(apiVersion)
+				defer server.Close()
+				server.PutObject("backends", "", map[string]interface{}{"name": "be_web"})
+
+				client := middleware.NewClient(dataplanetest.Username, dataplanetest.Password, server.Host(), true)
+				if err := client.SetAPIVersion(apiVersion); err != nil {
+					t.Fatal(err)
+				}
+				version := server.Version()
+
+				err := client.ValidateChange(context.Background(), func(ctx context.Context, transactionId string) error {
+					return tt.stage(ctx, client, transactionId)
+				})
+				if tt.wantErr == nil && err != nil || tt.wantErr != nil && !tt.wantErr(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if _, ok := server.Object("frontends", "", "fe_web"); ok || server.Version() != version {
+					t.Error("the validated change must not be committed")
+				}
+				if open := server.OpenTransactions(); open != 0 {
+					t.Errorf("%d transactions left open", open)
+				}
+			})
+		}
+	}
+}
@@ -16,12 +16,6 @@ type Backend struct {
 }
 
 type GetBackends struct {
-	Version int `json:"_version"`
-	Data    []struct {
-		Balance struct {
-			Algorithm string `json:"algorithm"`
-		} `json:"balance"`
-		Mode string `json:"mode"`
-		Name string `json:"name"`
-	} `json:"data"`
+	Version int       `json:"_version"`
+	Data    []Backend `json:"data"`
 }
@@ -12,10 +12,6 @@ type Bind struct {
 }
 
 type GetBinds struct {
-	Version int `json:"_version"`
-	Data    []struct {
-		Address string `json:"address"`
-		Name    string `json:"name"`
-		Port    int64 `json:"port"`
-	} `json:"data"`
+	Version int    `json:"_version"`
+	Data    []Bind `json:"data"`
 }
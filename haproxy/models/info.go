@@ -0,0 +1,18 @@
+package models
+
+type Info struct {
+	API struct {
+		BuildDate string `json:"build_date"`
+		Version   string `json:"version"`
+	} `json:"api"`
+	System struct {
+		Hostname string `json:"hostname"`
+	} `json:"system"`
+}
+
+type ProcessInfo struct {
+	Info struct {
+		Version     string `json:"version"`
+		ReleaseDate string `json:"release_date"`
+	} `json:"info"`
+}
@@ -0,0 +1,8 @@
+package models
+
+type Reload struct {
+	Id              string `json:"id"`
+	Status          string `json:"status"`
+	Response        string `json:"response"`
+	ReloadTimestamp int64  `json:"reload_timestamp"`
+}
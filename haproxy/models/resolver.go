@@ -1,8 +1,10 @@
 package models
 
+type Resolver struct {
+	Name string `json:"name"`
+}
+
 type GetResolvers struct {
-	Version int `json:"_version"`
-	Data    []struct {
-		Name   string `json:"name"`
-	} `json:"data"`
+	Version int        `json:"_version"`
+	Data    []Resolver `json:"data"`
 }
@@ -13,11 +13,6 @@ type Server struct {
 }
 
 type GetServers struct {
-	Version int `json:"_version"`
-	Data    []struct {
-		Address string `json:"address"`
-		Name    string `json:"name"`
-		Port    int64 `json:"port"`
-		Check   string `json:"check"`
-	} `json:"data"`
+	Version int      `json:"_version"`
+	Data    []Server `json:"data"`
 }
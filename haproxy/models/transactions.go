@@ -4,4 +4,7 @@ type Transaction struct {
 	Version int    `json:"_version"`
 	Id      string `json:"id"`
 	Status  string `json:"status"`
+
+	// Reload-ID header of the commit response, empty when no reload was scheduled
+	ReloadId string `json:"-"`
 }
@@ -2,17 +2,25 @@ package haproxy
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"terraform-provider-haproxy-pf/haproxy/middleware"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/net/http/httpguts"
 )
 
 // Ensure the implementation satisfies the expected interfaces
@@ -21,24 +29,68 @@ var (
 )
 
 // New is a helper function to simplify provider server and testing implementation.
-func New() provider.Provider {
-	return &haproxyProvider{}
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &haproxyProvider{
+			version: version,
+		}
+	}
 }
 
 // haproxyProvider is the provider implementation.
-type haproxyProvider struct{}
+type haproxyProvider struct {
+	// version of the provider, set at build time, "dev" for local builds
+	// and "test" for acceptance tests
+	version string
+}
 
 // haproxyProviderModel maps provider schema data to a Go type.
 type haproxyProviderModel struct {
 	Host     types.String `tfsdk:"host"`
+	Hosts    types.List   `tfsdk:"hosts"`
+	Nodes    types.List   `tfsdk:"nodes"`
+	Quorum   types.Int64  `tfsdk:"quorum"`
 	Username types.String `tfsdk:"username"`
 	Password types.String `tfsdk:"password"`
 	Insecure types.Bool   `tfsdk:"insecure"`
+
+	APIVersion types.String `tfsdk:"api_version"`
+
+	RetryAttempts types.Int64  `tfsdk:"retry_attempts"`
+	RetryDelay    types.String `tfsdk:"retry_delay"`
+	MaxBackoff    types.String `tfsdk:"max_backoff"`
+
+	BatchTransactions types.Bool   `tfsdk:"batch_transactions"`
+	BatchWindow       types.String `tfsdk:"batch_window"`
+
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
+	SerializeTransactions types.Bool    `tfsdk:"serialize_transactions"`
+
+	WaitForReload types.Bool   `tfsdk:"wait_for_reload"`
+	ReloadTimeout types.String `tfsdk:"reload_timeout"`
+	ReloadMode    types.String `tfsdk:"reload_mode"`
+
+	ValidateOnPlan   types.Bool `tfsdk:"validate_on_plan"`
+	StrictReferences types.Bool `tfsdk:"strict_references"`
+
+	RequestTimeout types.String `tfsdk:"request_timeout"`
+	HTTPProxy      types.String `tfsdk:"http_proxy"`
+	NoProxy        types.String `tfsdk:"no_proxy"`
+	Headers        types.Map    `tfsdk:"headers"`
+
+	CAFile             types.String `tfsdk:"ca_file"`
+	CAPEM              types.String `tfsdk:"ca_pem"`
+	ClientCert         types.String `tfsdk:"client_cert"`
+	ClientKey          types.String `tfsdk:"client_key"`
+	TLSServerName      types.String `tfsdk:"tls_server_name"`
+	InsecureSkipVerify types.Bool   `tfsdk:"insecure_skip_verify"`
 }
 
 // Metadata returns the provider type name.
 func (p *haproxyProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "haproxy-pf"
+	resp.Version = p.version
 }
 
 // Schema defines the provider-level schema for configuration data.
@@ -46,18 +98,135 @@ func (p *haproxyProvider) Schema(_ context.Context, _ provider.SchemaRequest, re
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"host": schema.StringAttribute{
-				Optional: true,
+				Optional:    true,
+				Description: "Address of the Data Plane API, e.g. \"localhost:5555\". Can also be set with the HAPROXY_HOST environment variable.",
+			},
+			"hosts": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Data Plane API instances, e.g. of a keepalived pair, used instead of host. Requests go to the first reachable one and stay on it until it becomes unreachable.",
+			},
+			"nodes": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Data Plane APIs of a cluster of HAProxy nodes sharing the same configuration, used instead of host. Every change is applied to each node in its own transaction, and reads warn about nodes that drifted.",
+			},
+			"quorum": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of nodes a change must be committed on for it to succeed. Defaults to all nodes. Nodes unreachable when the provider is configured are left out with a warning, and the configuration fails when fewer nodes than the quorum remain.",
 			},
 			"username": schema.StringAttribute{
-				Optional: true,
+				Optional:    true,
+				Description: "Can also be set with the HAPROXY_USERNAME environment variable.",
 			},
 			"password": schema.StringAttribute{
-				Optional:  true,
-				Sensitive: true,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Can also be set with the HAPROXY_PASSWORD environment variable.",
 			},
 			"insecure": schema.BoolAttribute{
-				Optional:  true,
-				Sensitive: false,
+				Optional:    true,
+				Sensitive:   false,
+				Description: "Use plain HTTP instead of HTTPS to reach the Data Plane API. Can also be set with the HAPROXY_INSECURE environment variable.",
+			},
+			"api_version": schema.StringAttribute{
+				Optional:    true,
+				Description: "Data Plane API layout: \"v2\", \"v3\" or \"auto\" to detect it from the server. Defaults to auto.",
+			},
+			"ca_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a PEM encoded CA bundle used to verify the Data Plane API certificate instead of the system trust store.",
+			},
+			"ca_pem": schema.StringAttribute{
+				Optional:    true,
+				Description: "PEM encoded CA bundle used to verify the Data Plane API certificate instead of the system trust store.",
+			},
+			"client_cert": schema.StringAttribute{
+				Optional:    true,
+				Description: "Client certificate for mutual TLS, as PEM content or file path.",
+			},
+			"client_key": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Private key of client_cert, as PEM content or file path.",
+			},
+			"tls_server_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "Server name used to verify the Data Plane API certificate, when it differs from host.",
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Skip verification of the Data Plane API certificate.",
+			},
+			"retry_attempts": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of attempts for operations failing with a retryable error (version conflict, 5xx, transport error). Defaults to 10.",
+			},
+			"retry_delay": schema.StringAttribute{
+				Optional:    true,
+				Description: "Base delay between two attempts, doubled after every attempt, e.g. \"500ms\". Defaults to 100ms.",
+			},
+			"max_backoff": schema.StringAttribute{
+				Optional:    true,
+				Description: "Upper bound for the delay between two attempts, e.g. \"10s\". Unbounded by default.",
+			},
+			"batch_transactions": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Group concurrent resource changes into a single Data Plane transaction, reducing HAProxy reloads and version conflicts. Defaults to false.",
+			},
+			"batch_window": schema.StringAttribute{
+				Optional:    true,
+				Description: "Quiet period after which a batched transaction is committed when no other change joined it. Defaults to 500ms.",
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Maximum number of requests in flight to the Data Plane API. Unlimited by default.",
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Maximum number of requests sent to the Data Plane API per second. Unlimited by default.",
+			},
+			"serialize_transactions": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Open Data Plane transactions one at a time, each on the configuration version committed by the previous one, so concurrent changes never conflict. Defaults to false.",
+			},
+			"wait_for_reload": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Wait for HAProxy to reload after every commit and fail if the reload fails. Defaults to false.",
+			},
+			"reload_timeout": schema.StringAttribute{
+				Optional:    true,
+				Description: "Maximum time to wait for a reload when wait_for_reload is set, e.g. \"30s\". Defaults to 2m.",
+			},
+			"reload_mode": schema.StringAttribute{
+				Optional:    true,
+				Description: "Reload behavior of the configuration changes: \"default\" lets the Data Plane API reload HAProxy, \"force\" reloads immediately on every commit and \"skip\" never reloads, leaving it to a haproxy-pf_reload resource. Resources can override it. Defaults to default.",
+				Validators:  []validator.String{reloadModeValidator()},
+			},
+			"validate_on_plan": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Validate planned changes during terraform plan by staging them in a transaction that is discarded, reporting the errors HAProxy finds in the resulting configuration. Changes depending on objects created by the same plan are only validated on apply. Defaults to false.",
+			},
+			"strict_references": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Fail the plan when a resource references a missing section, e.g. a frontend default_backend or a server parent_name, instead of warning. Only enable it when the referenced sections exist before the plan, as sections created by the same plan are reported missing. Defaults to false.",
+			},
+			"request_timeout": schema.StringAttribute{
+				Optional:    true,
+				Description: "Timeout of a single request to the Data Plane API, e.g. \"30s\". Can also be set with the HAPROXY_REQUEST_TIMEOUT environment variable. Defaults to 5m.",
+			},
+			"http_proxy": schema.StringAttribute{
+				Optional:    true,
+				Description: "URL of the HTTP proxy used to reach the Data Plane API. Can also be set with the HAPROXY_HTTP_PROXY environment variable. Defaults to the HTTP_PROXY and HTTPS_PROXY environment variables.",
+			},
+			"no_proxy": schema.StringAttribute{
+				Optional:    true,
+				Description: "Comma separated hosts, domains and CIDR ranges reached without proxy, e.g. \"10.0.0.0/8,.internal\". Can also be set with the HAPROXY_NO_PROXY environment variable. Defaults to the NO_PROXY environment variable.",
+			},
+			"headers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Additional HTTP headers sent with every request, e.g. for a gateway in front of the Data Plane API. Can also be set with the HAPROXY_HEADERS environment variable, as comma separated name=value pairs.",
 			},
 		},
 	}
@@ -81,6 +250,16 @@ func (p *haproxyProvider) Configure(ctx context.Context, req provider.ConfigureR
 			path.Root("host"), "Unknown haproxy API Host", "")
 	}
 
+	if config.Hosts.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("hosts"), "Unknown haproxy API Hosts", "")
+	}
+
+	if config.Nodes.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("nodes"), "Unknown haproxy API Nodes", "")
+	}
+
 	if config.Username.IsUnknown() {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("username"), "Unknown haproxy API Username", "")
@@ -107,11 +286,52 @@ func (p *haproxyProvider) Configure(ctx context.Context, req provider.ConfigureR
 	username := os.Getenv("HAPROXY_USERNAME")
 	password := os.Getenv("HAPROXY_PASSWORD")
 	insecure := false
+	if env := os.Getenv("HAPROXY_INSECURE"); env != "" {
+		var err error
+		insecure, err = strconv.ParseBool(env)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("insecure"), "Invalid haproxy API Insecure flag",
+				fmt.Sprintf("Expected true or false in HAPROXY_INSECURE, got %q", env))
+		}
+	}
 
 	if !config.Host.IsNull() {
 		host = config.Host.ValueString()
 	}
 
+	hosts := []string{}
+	if !config.Hosts.IsNull() {
+		resp.Diagnostics.Append(config.Hosts.ElementsAs(ctx, &hosts, false)...)
+		if !config.Host.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("hosts"), "Conflicting haproxy API Hosts", "Only one of host and hosts can be set")
+		}
+	} else if host != "" {
+		hosts = append(hosts, host)
+	}
+
+	nodes := []string{}
+	if !config.Nodes.IsNull() {
+		resp.Diagnostics.Append(config.Nodes.ElementsAs(ctx, &nodes, false)...)
+		if !config.Host.IsNull() || !config.Hosts.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("nodes"), "Conflicting haproxy API Hosts", "nodes cannot be combined with host or hosts")
+		}
+		// the HAPROXY_HOST default does not apply to clusters
+		hosts = nodes
+	}
+
+	quorum := len(nodes)
+	if !config.Quorum.IsNull() {
+		quorum = int(config.Quorum.ValueInt64())
+		if quorum < 1 || quorum > len(nodes) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("quorum"), "Invalid haproxy cluster quorum",
+				fmt.Sprintf("quorum must be between 1 and the number of nodes (%d)", len(nodes)))
+		}
+	}
+
 	if !config.Username.IsNull() {
 		username = config.Username.ValueString()
 	}
@@ -127,10 +347,16 @@ func (p *haproxyProvider) Configure(ctx context.Context, req provider.ConfigureR
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
-	if host == "" {
+	if len(hosts) == 0 {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("host"), "Unknown haproxy API Host", "")
 	}
+	for _, h := range hosts {
+		if h == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("hosts"), "Invalid haproxy API Hosts", "hosts and nodes cannot contain empty values")
+		}
+	}
 
 	if username == "" {
 		resp.Diagnostics.AddAttributeError(
@@ -143,11 +369,101 @@ func (p *haproxyProvider) Configure(ctx context.Context, req provider.ConfigureR
 
 	}
 
+	retryPolicy := middleware.DefaultRetryPolicy()
+
+	if !config.RetryAttempts.IsNull() {
+		if config.RetryAttempts.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("retry_attempts"), "Invalid haproxy retry attempts", "retry_attempts must be at least 1")
+		}
+		retryPolicy.Attempts = uint(config.RetryAttempts.ValueInt64())
+	}
+
+	retryPolicy.Delay = parseDuration(path.Root("retry_delay"), config.RetryDelay, retryPolicy.Delay, &resp.Diagnostics)
+	retryPolicy.MaxBackoff = parseDuration(path.Root("max_backoff"), config.MaxBackoff, retryPolicy.MaxBackoff, &resp.Diagnostics)
+
+	apiVersion := "auto"
+	if !config.APIVersion.IsNull() {
+		apiVersion = config.APIVersion.ValueString()
+	}
+	if apiVersion != "auto" && apiVersion != middleware.APIVersion2 && apiVersion != middleware.APIVersion3 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_version"), "Invalid haproxy API version",
+			fmt.Sprintf("Expected %s, %s or auto, got %q", middleware.APIVersion2, middleware.APIVersion3, apiVersion))
+	}
+
+	tlsOptions := middleware.TLSOptions{
+		CAFile:             config.CAFile.ValueString(),
+		CAPEM:              config.CAPEM.ValueString(),
+		ClientCert:         config.ClientCert.ValueString(),
+		ClientKey:          config.ClientKey.ValueString(),
+		ServerName:         config.TLSServerName.ValueString(),
+		InsecureSkipVerify: config.InsecureSkipVerify.ValueBool(),
+	}
+	tlsConfig, err := tlsOptions.Config()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid haproxy API TLS configuration", err.Error())
+	}
+
+	batchTransactions := config.BatchTransactions.ValueBool()
+	batchWindow := parseDuration(path.Root("batch_window"), config.BatchWindow, 500*time.Millisecond, &resp.Diagnostics)
+
+	reloadTimeout := parseDuration(path.Root("reload_timeout"), config.ReloadTimeout, 2*time.Minute, &resp.Diagnostics)
+
+	requestTimeout := config.RequestTimeout
+	if requestTimeout.IsNull() && os.Getenv("HAPROXY_REQUEST_TIMEOUT") != "" {
+		requestTimeout = types.StringValue(os.Getenv("HAPROXY_REQUEST_TIMEOUT"))
+	}
+	timeout := parseDuration(path.Root("request_timeout"), requestTimeout, 5*time.Minute, &resp.Diagnostics)
+
+	httpProxy := os.Getenv("HAPROXY_HTTP_PROXY")
+	if !config.HTTPProxy.IsNull() {
+		httpProxy = config.HTTPProxy.ValueString()
+	}
+	noProxy := os.Getenv("HAPROXY_NO_PROXY")
+	if !config.NoProxy.IsNull() {
+		noProxy = config.NoProxy.ValueString()
+	}
+
+	headers, err := parseHeaders(os.Getenv("HAPROXY_HEADERS"))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("headers"), "Invalid haproxy API headers", "HAPROXY_HEADERS: "+err.Error())
+	}
+	if !config.Headers.IsNull() {
+		headers = map[string]string{}
+		resp.Diagnostics.Append(config.Headers.ElementsAs(ctx, &headers, false)...)
+	}
+	for name := range headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("headers"), "Invalid haproxy API headers", fmt.Sprintf("%q is not a valid header name", name))
+		}
+	}
+
+	if httpProxy != "" {
+		if _, err := url.Parse(httpProxy); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("http_proxy"), "Invalid haproxy API proxy", "invalid proxy URL: "+err.Error())
+		}
+	}
+
+	userAgent := fmt.Sprintf("Terraform/%s (+https://www.terraform.io) terraform-provider-haproxy-pf/%s", req.TerraformVersion, p.version)
+
+	if config.MaxConcurrentRequests.ValueInt64() < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("max_concurrent_requests"), "Invalid haproxy max concurrent requests", "max_concurrent_requests must be positive")
+	}
+	if config.RequestsPerSecond.ValueFloat64() < 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("requests_per_second"), "Invalid haproxy requests per second", "requests_per_second must be positive")
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	ctx = tflog.SetField(ctx, "haproxy_host", host)
+	ctx = tflog.SetField(ctx, "haproxy_host", strings.Join(hosts, ","))
 	ctx = tflog.SetField(ctx, "haproxy_username", username)
 	ctx = tflog.SetField(ctx, "haproxy_password", password)
 	ctx = tflog.SetField(ctx, "haproxy_insecure", insecure)
@@ -155,8 +471,104 @@ func (p *haproxyProvider) Configure(ctx context.Context, req provider.ConfigureR
 
 	tflog.Info(ctx, "Creating Haproxy client")
 
-	// Create a new Haproxy client using the configuration values
-	client := middleware.NewClient(username, password, host, insecure)
+	// Create a new Haproxy client using the configuration values, failing
+	// with an error diagnostic when the Data Plane API cannot be used
+	connect := func(hosts []string) (*middleware.Client, diag.Diagnostic) {
+		client := middleware.NewClient(username, password, hosts[0], insecure)
+		_ = client.SetHosts(hosts)
+		client.RetryPolicy = retryPolicy
+		client.SetTLSConfig(tlsConfig)
+		client.SetTimeout(timeout)
+		if httpProxy != "" || noProxy != "" {
+			// the proxy URL is validated above
+			_ = client.SetProxy(httpProxy, noProxy)
+		}
+		client.SetUserAgent(userAgent)
+		client.SetHeaders(headers)
+		if batchTransactions {
+			client.EnableBatching(batchWindow)
+		}
+		client.SetConcurrencyLimit(int(config.MaxConcurrentRequests.ValueInt64()))
+		client.SetRateLimit(config.RequestsPerSecond.ValueFloat64())
+		client.SerializeTransactions(config.SerializeTransactions.ValueBool())
+		if config.WaitForReload.ValueBool() {
+			client.WaitForReloads(reloadTimeout)
+		}
+		if !config.ReloadMode.IsNull() {
+			_ = client.SetReloadMode(config.ReloadMode.ValueString())
+		}
+		client.SetValidateOnPlan(config.ValidateOnPlan.ValueBool())
+		client.SetStrictReferences(config.StrictReferences.ValueBool())
+
+		clientAPIVersion := apiVersion
+		if clientAPIVersion == "auto" {
+			clientAPIVersion, err = client.DetectAPIVersion(ctx)
+			if err != nil {
+				return nil, diag.NewErrorDiagnostic("Unable to detect haproxy API version", client.Host()+": "+err.Error())
+			}
+		} else {
+			_ = client.SetAPIVersion(clientAPIVersion)
+		}
+
+		if err := client.Negotiate(ctx); err != nil {
+			return nil, diag.NewErrorDiagnostic(
+				"Unsupported haproxy API",
+				"Could not negotiate the Data Plane API version of "+client.Host()+": "+err.Error(),
+			)
+		}
+		if err := client.DetectHAProxyVersion(ctx); err != nil {
+			resp.Diagnostics.AddWarning(
+				"Unknown HAProxy version",
+				"Could not read the HAProxy version of "+client.Host()+", the checks depending on it are skipped: "+err.Error(),
+			)
+		} else if !client.SupportsHAProxy(middleware.MinHAProxyVersion) {
+			resp.Diagnostics.AddWarning(
+				"Unsupported HAProxy version",
+				fmt.Sprintf("HAProxy %s on %s is older than %s, some attributes may be rejected.", client.HAProxyVersion(), client.Host(), middleware.MinHAProxyVersion),
+			)
+		}
+		tflog.Info(ctx, "Negotiated Haproxy Data Plane API", map[string]any{
+			"api_version":       clientAPIVersion,
+			"endpoint":          client.Host(),
+			"dataplane_version": client.DataPlaneVersion(),
+			"haproxy_version":   client.HAProxyVersion(),
+		})
+		return client, nil
+	}
+
+	var client *middleware.Client
+	if len(nodes) > 0 {
+		// the cluster is made of the reachable nodes, as long as they
+		// can reach the quorum
+		nodeClients := make([]*middleware.Client, 0, len(nodes))
+		for _, node := range nodes {
+			nodeClient, failure := connect([]string{node})
+			if failure != nil {
+				resp.Diagnostics.AddWarning(
+					"Haproxy cluster node left out",
+					fmt.Sprintf("%s: %s. Changes are not applied to this node and it is not checked for drift until it is reachable again.", failure.Summary(), failure.Detail()),
+				)
+				continue
+			}
+			nodeClients = append(nodeClients, nodeClient)
+		}
+		if len(nodeClients) < quorum {
+			resp.Diagnostics.AddError(
+				"Haproxy cluster quorum not reached",
+				fmt.Sprintf("Only %d of the %d nodes are usable, the quorum is %d.", len(nodeClients), len(nodes), quorum),
+			)
+			return
+		}
+		client = nodeClients[0]
+		_ = client.SetCluster(nodeClients, quorum)
+	} else {
+		var failure diag.Diagnostic
+		client, failure = connect(hosts)
+		if failure != nil {
+			resp.Diagnostics.Append(failure)
+			return
+		}
+	}
 
 	// Make the Haproxy client available during DataSource and Resource
 	// type Configure methods.
@@ -167,6 +579,41 @@ func (p *haproxyProvider) Configure(ctx context.Context, req provider.ConfigureR
 
 }
 
+// parseDuration returns the duration configured in value, or defaultValue if
+// it is null. Parsing errors are added to diags.
+func parseDuration(attributePath path.Path, value types.String, defaultValue time.Duration, diags *diag.Diagnostics) time.Duration {
+	if value.IsNull() || value.IsUnknown() {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value.ValueString())
+	if err != nil || duration < 0 {
+		diags.AddAttributeError(
+			attributePath, "Invalid duration",
+			fmt.Sprintf("Expected a positive duration such as \"500ms\" or \"10s\", got %q", value.ValueString()))
+		return defaultValue
+	}
+
+	return duration
+}
+
+// parseHeaders parses HTTP headers formatted as comma separated name=value
+// pairs, as in HAPROXY_HEADERS.
+func parseHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, headerValue, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("expected name=value pairs, got %q", pair)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+	}
+	return headers, nil
+}
+
 // DataSources defines the data sources implemented in the provider.
 func (p *haproxyProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
@@ -184,5 +631,6 @@ func (p *haproxyProvider) Resources(_ context.Context) []func() resource.Resourc
 		NewBindResource,
 		NewServerResource,
 		NewServerTemplateResource,
+		NewReloadResource,
 	}
 }
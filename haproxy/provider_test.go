@@ -1,8 +1,13 @@
 package haproxy
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
+	"terraform-provider-haproxy-pf/haproxy/dataplanetest"
 	"terraform-provider-haproxy-pf/haproxy/middleware"
 	"testing"
 
@@ -10,37 +15,108 @@ import (
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 )
 
-const (
-	// providerConfig is a shared configuration to combine with the actual
-	// test configuration so the Haproxy client is properly configured.
-	// It is also possible to use the HAPROXY_ environment variables instead,
-	// such as updating the Makefile and running the testing through that tool.
-	providerConfig = `
+// providerConfigTemplate is formatted with the username, password and
+// target, host or nodes, of the Data Plane APIs under test and with extra
+// settings, see formatProviderConfig.
+const providerConfigTemplate = `
 provider "haproxy-pf" {
-  username = "admin"
-  password = "adminpwd"
-	host     = "localhost:5555"
-	insecure = true
-}
+  username = %q
+  password = %q
+  %s
+  insecure = true
+%s}
 `
-)
 
 var (
+	// providerConfig is a shared configuration to combine with the actual
+	// test configuration so the Haproxy client is properly configured.
+	// It targets HAPROXY_SERVER when set, otherwise the in-process fake
+	// Data Plane API started by TestMain.
+	providerConfig string
+
 	// testAccProtoV6ProviderFactories are used to instantiate a provider during
 	// acceptance testing. The factory function will be invoked for every Terraform
 	// CLI command executed to create a provider server to which the CLI can
 	// reattach.
 	testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-		"haproxy-pf": providerserver.NewProtocol6WithError(New()),
+		"haproxy-pf": providerserver.NewProtocol6WithError(New("test")()),
 	}
 	testAccProviders map[string]*haproxyProvider
 	testAccProvider  *haproxyProvider
 )
 
+func TestMain(m *testing.M) {
+	if os.Getenv("HAPROXY_SERVER") == "" {
+		server := dataplanetest.NewServer()
+		os.Setenv("HAPROXY_SERVER", server.Host())
+		os.Setenv("HAPROXY_USERNAME", dataplanetest.Username)
+		os.Setenv("HAPROXY_PASSWORD", dataplanetest.Password)
+		os.Setenv("HAPROXY_INSECURE", "true")
+		code := runTests(m)
+		server.Close()
+		os.Exit(code)
+	}
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	providerConfig = formatProviderConfig(os.Getenv("HAPROXY_USERNAME"), os.Getenv("HAPROXY_PASSWORD"),
+		fmt.Sprintf("host     = %q", os.Getenv("HAPROXY_SERVER")))
+	return m.Run()
+}
+
+// formatProviderConfig returns a provider configuration with the target and
+// the extra settings lines, e.g. "validate_on_plan = true".
+func formatProviderConfig(username string, password string, target string, settings ...string) string {
+	var extra strings.Builder
+	for _, setting := range settings {
+		extra.WriteString("  " + setting + "\n")
+	}
+	return fmt.Sprintf(providerConfigTemplate, username, password, target, extra.String())
+}
+
+// testProviderConfig returns a provider configuration targeting fake Data
+// Plane APIs, as a cluster when there are several, with extra settings.
+func testProviderConfig(servers []*dataplanetest.Server, settings ...string) string {
+	if len(servers) == 1 {
+		return formatProviderConfig(dataplanetest.Username, dataplanetest.Password,
+			fmt.Sprintf("host     = %q", servers[0].Host()), settings...)
+	}
+
+	hosts := make([]string, 0, len(servers))
+	for _, server := range servers {
+		hosts = append(hosts, strconv.Quote(server.Host()))
+	}
+	return formatProviderConfig(dataplanetest.Username, dataplanetest.Password,
+		"nodes    = ["+strings.Join(hosts, ", ")+"]", settings...)
+}
+
+// newTestServer starts a fake Data Plane API dedicated to a test, for tests
+// changing it out of band or needing extra provider settings, and returns it
+// with a provider configuration targeting it.
+func newTestServer(t *testing.T, settings ...string) (*dataplanetest.Server, string) {
+	server := dataplanetest.NewServer()
+	t.Cleanup(server.Close)
+	return server, testProviderConfig([]*dataplanetest.Server{server}, settings...)
+}
+
+// newTestCluster starts size fake Data Plane APIs dedicated to a test, to be
+// configured as a cluster with testProviderConfig.
+func newTestCluster(t *testing.T, size int) []*dataplanetest.Server {
+	nodes := make([]*dataplanetest.Server, 0, size)
+	for i := 0; i < size; i++ {
+		node := dataplanetest.NewServer()
+		t.Cleanup(node.Close)
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
 func TestAccMain(t *testing.T) {
 	if os.Getenv("TF_ACC") == "" {
-		// short circuit non-acceptance test runs
-		os.Exit(1)
+		// short circuit non-acceptance test runs, the other tests of the
+		// package run offline
+		t.Skip("Acceptance tests skipped unless env 'TF_ACC' set")
 	}
 
 	serverAddr := os.Getenv("HAPROXY_SERVER")
@@ -50,8 +126,33 @@ func TestAccMain(t *testing.T) {
 
 	testClient := middleware.NewClient(username, password, serverAddr, insecure)
 
-	err := testClient.TestApiCall()
+	err := testClient.TestApiCall(context.Background())
 	if err != nil {
 		panic(err)
 	}
 }
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{value: "", want: map[string]string{}},
+		{value: "X-Gateway-Key=secret", want: map[string]string{"X-Gateway-Key": "secret"}},
+		{value: " X-A = 1 ,X-B=a=b,", want: map[string]string{"X-A": "1", "X-B": "a=b"}},
+		{value: "X-A", wantErr: true},
+		{value: "=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseHeaders(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHeaders(%q) returned error %v", tt.value, err)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseHeaders(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
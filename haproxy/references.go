@@ -0,0 +1,87 @@
+package haproxy
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// sectionReference is an attribute naming a section of the configuration.
+type sectionReference struct {
+	attribute string
+	// section is the kind of the referenced section, e.g. backend
+	section string
+	name    types.String
+	// get reads the referenced section, failing with a not found error
+	// when it does not exist
+	get func(ctx context.Context, name string) error
+}
+
+// backendReference returns the reference of attribute to a backend.
+func backendReference(client *middleware.Client, attribute string, name types.String) sectionReference {
+	return sectionReference{attribute: attribute, section: "backend", name: name, get: func(ctx context.Context, name string) error {
+		_, err := client.GetBackend(ctx, name)
+		return err
+	}}
+}
+
+// frontendReference returns the reference of attribute to a frontend.
+func frontendReference(client *middleware.Client, attribute string, name types.String) sectionReference {
+	return sectionReference{attribute: attribute, section: "frontend", name: name, get: func(ctx context.Context, name string) error {
+		_, err := client.GetFrontend(ctx, name)
+		return err
+	}}
+}
+
+// resolversReference returns the reference of attribute to a resolvers section.
+func resolversReference(client *middleware.Client, attribute string, name types.String) sectionReference {
+	return sectionReference{attribute: attribute, section: "resolvers", name: name, get: func(ctx context.Context, name string) error {
+		_, err := client.GetResolver(ctx, name)
+		return err
+	}}
+}
+
+// checksReferences reports whether the references of the plan of req must
+// be checked with checkReferences.
+func checksReferences(client *middleware.Client, req resource.ModifyPlanRequest) bool {
+	// the provider is not configured during validation, destroys have no
+	// references and those of unchanged resources were already checked
+	return client != nil && !req.Plan.Raw.IsNull() && !req.Plan.Raw.Equal(req.State.Raw)
+}
+
+// checkReferences reports the references to sections missing from the live
+// configuration, as errors with strict_references and as warnings otherwise
+// since the plan may create them. Unknown and empty references are skipped.
+func checkReferences(ctx context.Context, client *middleware.Client, diags *diag.Diagnostics, references ...sectionReference) {
+	for _, reference := range references {
+		if reference.name.IsNull() || reference.name.IsUnknown() || reference.name.ValueString() == "" {
+			continue
+		}
+
+		name := reference.name.ValueString()
+		err := reference.get(ctx, name)
+		switch {
+		case err == nil:
+		case middleware.IsNotFound(err):
+			summary := "Missing " + reference.section
+			detail := fmt.Sprintf("The %s %q does not exist in the haproxy configuration.", reference.section, name)
+			if client.StrictReferences() {
+				diags.AddAttributeError(path.Root(reference.attribute), summary, detail)
+			} else {
+				diags.AddAttributeWarning(path.Root(reference.attribute), summary, detail+" The apply fails unless it is created by the same plan.")
+			}
+		default:
+			diags.AddAttributeWarning(
+				path.Root(reference.attribute),
+				"Could not check "+reference.section+" reference",
+				fmt.Sprintf("The %s %q could not be read, the reference is only checked on apply: %s", reference.section, name, err.Error()),
+			)
+		}
+	}
+}
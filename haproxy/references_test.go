@@ -0,0 +1,97 @@
+package haproxy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccStrictReferences(t *testing.T) {
+	server, strictConfig := newTestServer(t, "strict_references = true")
+
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	templateName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	server.PutObject("backends", "", map[string]interface{}{"name": backendName})
+	server.PutObject("resolvers", "", map[string]interface{}{"name": "dns"})
+
+	var requests int
+	templateConfig := func(parentName string, resolvers string) string {
+		return strictConfig + fmt.Sprintf(`
+		resource "haproxy-pf_server_template" "%s" {
+			prefix = "%s"
+			fqdn = "backend.example.com"
+			num_or_range = "1-3"
+			port = 80
+			check = "enabled"
+			resolvers = "%s"
+			parent_name = "%s"
+		}
+		`, templateName, templateName, resolvers, parentName)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      templateConfig("missing", "dns"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The backend "missing" does not exist`),
+			},
+			{
+				Config:      templateConfig(backendName, "missing"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The resolvers "missing" does not exist`),
+			},
+			{
+				Config: templateConfig(backendName, "dns"),
+				Check:  resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_server_template.%s", templateName), "resolvers", "dns"),
+			},
+			// the references of unchanged resources are not checked again
+			{
+				PreConfig: func() { requests = len(server.Requests()) },
+				Config:    templateConfig(backendName, "dns"),
+				Check: func(*terraform.State) error {
+					for _, request := range server.Requests()[requests:] {
+						if strings.HasSuffix(request, "/backends/"+backendName) || strings.HasSuffix(request, "/resolvers/dns") {
+							return fmt.Errorf("references of the unchanged server template checked with %s", request)
+						}
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccReferencesCreatedByThePlan(t *testing.T) {
+	_, config := newTestServer(t)
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	frontendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	// the missing backend is only a warning without strict_references
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config + fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "roundrobin"
+					mode = "http"
+				}
+
+				resource "haproxy-pf_frontend" "%s" {
+					name = "%s"
+					default_backend = haproxy-pf_backend.%s.name
+				}
+				`, backendName, backendName, frontendName, frontendName, backendName),
+				Check: resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_frontend.%s", frontendName), "default_backend", backendName),
+			},
+		},
+	})
+}
@@ -2,24 +2,27 @@ package haproxy
 
 import (
 	"context"
+	"fmt"
 	"terraform-provider-haproxy-pf/haproxy/middleware"
 	"terraform-provider-haproxy-pf/haproxy/models"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-
-	"github.com/avast/retry-go/v4"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &backendResource{}
-	_ resource.ResourceWithConfigure   = &backendResource{}
-	_ resource.ResourceWithImportState = &backendResource{}
+	_ resource.Resource                 = &backendResource{}
+	_ resource.ResourceWithConfigure    = &backendResource{}
+	_ resource.ResourceWithImportState  = &backendResource{}
+	_ resource.ResourceWithModifyPlan   = &backendResource{}
+	_ resource.ResourceWithUpgradeState = &backendResource{}
 )
 
 // NewBackendResource is a helper function to simplify the provider implementation.
@@ -34,10 +37,11 @@ type backendResource struct {
 
 // backendsModel maps backends schema data.
 type backendResourceModel struct {
-	ID      types.String `tfsdk:"id"`
-	Name    types.String `tfsdk:"name"`
-	Mode    types.String `tfsdk:"mode"`
-	Balance types.String `tfsdk:"balance"`
+	ID         types.String `tfsdk:"id"`
+	Name       types.String `tfsdk:"name"`
+	Mode       types.String `tfsdk:"mode"`
+	Balance    types.String `tfsdk:"balance"`
+	ReloadMode types.String `tfsdk:"reload_mode"`
 }
 
 // Metadata returns the resource type name.
@@ -48,6 +52,8 @@ func (r *backendResource) Metadata(_ context.Context, req resource.MetadataReque
 // Schema defines the schema for the resource.
 func (r *backendResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		// bump with a new UpgradeState entry when the state changes
+		Version: 1,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed: true,
@@ -58,14 +64,21 @@ func (r *backendResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Description: "Name of the backend, made of " + identifierCharacters + ".",
+				Validators:  []validator.String{identifierValidator()},
 			},
 			"mode": schema.StringAttribute{
-				Optional: true,
+				Optional:    true,
+				Description: "http or tcp.",
+				Validators:  []validator.String{stringvalidator.OneOf(proxyModes...)},
 			},
 			"balance": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required:    true,
+				Optional:    false,
+				Description: "Load balancing algorithm: roundrobin, static-rr, leastconn, first, source, uri, url_param, hdr, random, rdp-cookie or hash, which requires HAProxy 2.6.",
+				Validators:  []validator.String{stringvalidator.OneOf(balanceAlgorithms...)},
 			},
+			"reload_mode": reloadModeAttribute,
 		},
 	}
 }
@@ -101,33 +114,22 @@ func (r *backendResource) Create(ctx context.Context, req resource.CreateRequest
 	}
 
 	var response *models.Backend
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Create new backend
-			create_response, err := r.client.CreateBackend(transaction.Id, payload)
-			if err != nil {
-				return err
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			create_response, err := r.client.CreateBackend(ctx, transactionId, payload)
 			if err != nil {
 				return err
 			}
 			response = create_response
 			return nil
-		},
-	)
+		})
+	})
 
-	if retry_err != nil {
+	// a failed reload leaves the backend committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error creating backend",
 			"Could not create backend, unexpected error: "+retry_err.Error(),
@@ -148,6 +150,8 @@ func (r *backendResource) Create(ctx context.Context, req resource.CreateRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	addReloadError(&resp.Diagnostics, "backend", retry_err)
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -163,8 +167,16 @@ func (r *backendResource) Read(ctx context.Context, req resource.ReadRequest, re
 
 	_, backendName, _ := middleware.ResourceParseId(ctx, state.ID.String())
 
+	ctx, reportDrift := withDriftWarnings(ctx)
+	defer reportDrift(&resp.Diagnostics)
+
 	// Get refreshed backend
-	response, err := r.client.GetBackend(backendName)
+	response, err := r.client.GetBackend(ctx, backendName)
+	if middleware.IsNotFound(err) {
+		// the backend was deleted outside of Terraform, plan its re-creation
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy Backend",
@@ -221,31 +233,20 @@ func (r *backendResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Update existing backend
-			_, err = r.client.UpdateBackend(transaction.Id, backendName, payload)
-			if err != nil {
-				return err
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			_, err := r.client.UpdateBackend(ctx, transactionId, backendName, payload)
 			if err != nil {
 				return err
 			}
 			return nil
-		},
-	)
-	if retry_err != nil {
+		})
+	})
+	// a failed reload leaves the backend committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error updating backend",
 			"Could not update backend, unexpected error: "+retry_err.Error(),
@@ -256,7 +257,7 @@ func (r *backendResource) Update(ctx context.Context, req resource.UpdateRequest
 
 	// Fetch updated items from GetOrder as UpdateOrder items are not
 	// populated.
-	response, err := r.client.GetBackend(backendName)
+	response, err := r.client.GetBackend(ctx, backendName)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy Backend",
@@ -278,6 +279,7 @@ func (r *backendResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
+	addReloadError(&resp.Diagnostics, "backend", retry_err)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -293,30 +295,21 @@ func (r *backendResource) Delete(ctx context.Context, req resource.DeleteRequest
 
 	_, backendName, _ := middleware.ResourceParseId(ctx, state.ID.String())
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
-			// Delete existing backend
-			err = r.client.DeleteBackend(transaction.Id, backendName)
-			if err != nil {
-				return err
+	ctx = withReloadMode(ctx, state.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
+			// Delete existing backend, there is nothing to commit when it is already deleted
+			err := r.client.DeleteBackend(ctx, transactionId, backendName)
+			if middleware.IsNotFound(err) {
+				return middleware.ErrNothingToCommit
 			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
 			if err != nil {
 				return err
 			}
 			return nil
-		},
-	)
+		})
+	})
 	if retry_err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting backend",
@@ -327,6 +320,69 @@ func (r *backendResource) Delete(ctx context.Context, req resource.DeleteRequest
 
 }
 
+// ModifyPlan checks that HAProxy supports the balance algorithm of the
+// planned backend and validates it when validate_on_plan is enabled.
+func (r *backendResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if r.client == nil || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	// Retrieve values from plan
+	var plan backendResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if minimum, ok := balanceMinHAProxyVersions[plan.Balance.ValueString()]; ok && !r.client.SupportsHAProxy(minimum) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("balance"),
+			"Unsupported balance algorithm",
+			fmt.Sprintf("The %s algorithm requires HAProxy %s or later, %s runs HAProxy %s.", plan.Balance.ValueString(), minimum, r.client.Host(), r.client.HAProxyVersion()),
+		)
+		return
+	}
+	if !validatesPlan(r.client, req) {
+		return
+	}
+
+	// generate api request payload
+	var balance = models.Balance{
+		Algorithm: plan.Balance.ValueString(),
+	}
+	var payload = models.Backend{
+		Name:    plan.Name.ValueString(),
+		Mode:    plan.Mode.ValueString(),
+		Balance: balance,
+	}
+
+	validatePlan(ctx, r.client, func(ctx context.Context, transactionId string) error {
+		if createsObject(req, resp) {
+			_, err := r.client.CreateBackend(ctx, transactionId, payload)
+			return err
+		}
+		_, err := r.client.UpdateBackend(ctx, transactionId, plan.Name.ValueString(), payload)
+		return err
+	}, &resp.Diagnostics)
+}
+
+// UpgradeState migrates the states of the previous schema versions.
+func (r *backendResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// version 0 is unchanged
+		0: upgradeState[backendResourceModel](schema.Schema{
+			Attributes: map[string]schema.Attribute{
+				"id":          schema.StringAttribute{Computed: true},
+				"name":        schema.StringAttribute{Optional: true},
+				"mode":        schema.StringAttribute{Optional: true},
+				"balance":     schema.StringAttribute{Optional: true},
+				"reload_mode": schema.StringAttribute{Optional: true},
+			},
+		}, nil),
+	}
+}
+
 func (r *backendResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Retrieve import ID and save to id attribute
 	_, backendName, err := middleware.ResourceParseId(ctx, req.ID)
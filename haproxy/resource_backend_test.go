@@ -2,10 +2,13 @@ package haproxy
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccBackendResource(t *testing.T) {
@@ -76,3 +79,198 @@ func TestAccBackendResource(t *testing.T) {
 		},
 	})
 }
+
+func TestAccBackendResourceDeletedOutOfBand(t *testing.T) {
+	server, config := newTestServer(t)
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	backendConfig := config + fmt.Sprintf(`
+	resource "haproxy-pf_backend" "%s" {
+		name = "%s"
+		balance = "roundrobin"
+		mode = "http"
+	}
+	`, backendName, backendName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: backendConfig,
+			},
+			// a backend deleted by hand is created again
+			{
+				PreConfig: func() { server.DeleteObject("backends", "", backendName) },
+				Config:    backendConfig,
+				Check: func(*terraform.State) error {
+					if _, ok := server.Object("backends", "", backendName); !ok {
+						return fmt.Errorf("backend %s was not created again", backendName)
+					}
+					return nil
+				},
+			},
+			// destroying a backend deleted by hand succeeds
+			{
+				PreConfig: func() { server.DeleteObject("backends", "", backendName) },
+				Config:    backendConfig,
+				Destroy:   true,
+			},
+		},
+	})
+}
+
+func TestAccBackendResourceDeletedBeforeDestroy(t *testing.T) {
+	server, config := newTestServer(t)
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	backendConfig := config + fmt.Sprintf(`
+	resource "haproxy-pf_backend" "%s" {
+		name = "%s"
+		balance = "roundrobin"
+		mode = "http"
+	}
+	`, backendName, backendName)
+
+	var requests, reloads int
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: backendConfig,
+			},
+			// the backend is deleted by hand after the refresh, when the
+			// destroy opens its transaction
+			{
+				PreConfig: func() {
+					requests, reloads = len(server.Requests()), len(server.Reloads())
+					server.BeforeNextTransaction(func() { server.DeleteObject("backends", "", backendName) })
+				},
+				Config:  backendConfig,
+				Destroy: true,
+				Check: func(*terraform.State) error {
+					deleted := false
+					for _, request := range server.Requests()[requests:] {
+						deleted = deleted || (strings.HasPrefix(request, "DELETE ") && strings.HasSuffix(request, "/backends/"+backendName))
+					}
+					if !deleted {
+						return fmt.Errorf("backend %s was not deleted by the provider", backendName)
+					}
+					if got := len(server.Reloads()); got != reloads {
+						return fmt.Errorf("destroying a deleted backend reloaded HAProxy %d times", got-reloads)
+					}
+					if open := server.OpenTransactions(); open != 0 {
+						return fmt.Errorf("%d transactions left open", open)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccBackendResourceBalanceRequiresHAProxyVersion(t *testing.T) {
+	server, config := newTestServer(t)
+	server.SetHAProxyVersion("2.4.22")
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config + fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "hash"
+					mode = "http"
+				}
+				`, backendName, backendName),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`The hash algorithm requires HAProxy 2.6 or later`),
+			},
+		},
+	})
+}
+
+func TestAccBackendResourceHAProxyVersionUnknown(t *testing.T) {
+	server, config := newTestServer(t)
+	server.SetHAProxyVersion("")
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	// the provider is configured without the HAProxy version and assumes
+	// every algorithm is supported
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config + fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "hash"
+					mode = "http"
+				}
+				`, backendName, backendName),
+				Check: resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_backend.%s", backendName), "balance", "hash"),
+			},
+		},
+	})
+}
+
+func TestAccBackendResourceAlreadyExists(t *testing.T) {
+	server, config := newTestServer(t)
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	server.PutObject("backends", "", map[string]interface{}{"name": backendName})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the conflict is reported without retrying
+			{
+				Config: config + fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "roundrobin"
+					mode = "http"
+				}
+				`, backendName, backendName),
+				ExpectError: regexp.MustCompile("already exists"),
+			},
+		},
+	})
+	creates := 0
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request, "POST ") && strings.HasSuffix(request, "/backends") {
+			creates++
+		}
+	}
+	if creates != 1 {
+		t.Errorf("backend creation attempted %d times, want 1", creates)
+	}
+}
+
+func TestAccBackendResourceReloadFailure(t *testing.T) {
+	server, config := newTestServer(t, "wait_for_reload = true")
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	backendConfig := config + fmt.Sprintf(`
+	resource "haproxy-pf_backend" "%s" {
+		name = "%s"
+		balance = "roundrobin"
+		mode = "http"
+	}
+	`, backendName, backendName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the committed backend is kept in state despite the failed reload
+			{
+				PreConfig:   func() { server.FailReloads("[ALERT] parsing error") },
+				Config:      backendConfig,
+				ExpectError: regexp.MustCompile("Haproxy reload failed"),
+			},
+			// and replaced once HAProxy reloads again
+			{
+				PreConfig: func() { server.FailReloads("") },
+				Config:    backendConfig,
+				Check:     resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_backend.%s", backendName), "id", "root/"+backendName),
+			},
+		},
+	})
+}
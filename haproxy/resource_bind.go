@@ -10,16 +10,17 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-
-	"github.com/avast/retry-go/v4"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &bindResource{}
-	_ resource.ResourceWithConfigure   = &bindResource{}
-	_ resource.ResourceWithImportState = &bindResource{}
+	_ resource.Resource                 = &bindResource{}
+	_ resource.ResourceWithConfigure    = &bindResource{}
+	_ resource.ResourceWithImportState  = &bindResource{}
+	_ resource.ResourceWithModifyPlan   = &bindResource{}
+	_ resource.ResourceWithUpgradeState = &bindResource{}
 )
 
 // NewBindResource is a helper function to simplify the provider implementation.
@@ -39,6 +40,7 @@ type bindResourceModel struct {
 	Address    types.String `tfsdk:"address"`
 	Port       types.Int64  `tfsdk:"port"`
 	ParentName types.String `tfsdk:"parent_name"`
+	ReloadMode types.String `tfsdk:"reload_mode"`
 }
 
 // Metadata returns the resource type name.
@@ -49,9 +51,12 @@ func (r *bindResource) Metadata(_ context.Context, req resource.MetadataRequest,
 // Schema defines the schema for the resource.
 func (r *bindResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		// bump with a new UpgradeState entry when the state changes
+		Version: 1,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Computed: true,
+				Computed:    true,
+				Description: "frontend/<parent_name>/<name>, with the names path escaped.",
 			},
 			"name": schema.StringAttribute{
 				Required: true,
@@ -59,14 +64,18 @@ func (r *bindResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Description: "Name of the bind, made of " + serverNameCharacters + ".",
+				Validators:  []validator.String{serverNameValidator()},
 			},
 			"address": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required:   true,
+				Optional:   false,
+				Validators: []validator.String{addressValidator{allowWildcard: true}},
 			},
 			"port": schema.Int64Attribute{
-				Required: true,
-				Optional: false,
+				Required:   true,
+				Optional:   false,
+				Validators: []validator.Int64{portValidator()},
 			},
 			"parent_name": schema.StringAttribute{
 				Required: true,
@@ -74,7 +83,9 @@ func (r *bindResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{identifierValidator()},
 			},
+			"reload_mode": reloadModeAttribute,
 		},
 	}
 }
@@ -107,32 +118,22 @@ func (r *bindResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	var response *models.Bind
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Create new bind
-			create_response, err := r.client.CreateBind(transaction.Id, payload, plan.ParentName.ValueString())
-			if err != nil {
-				return nil
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			create_response, err := r.client.CreateBind(ctx, transactionId, payload, plan.ParentName.ValueString())
 			if err != nil {
 				return err
 			}
 			response = create_response
 			return nil
 		})
+	})
 
-	if retry_err != nil {
+	// a failed reload leaves the bind committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error creating bind",
 			"Could not create bind, unexpected error: "+retry_err.Error(),
@@ -140,7 +141,7 @@ func (r *bindResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	id := middleware.CreateResourceId(plan.ParentName.ValueString(), response.Name)
+	id := middleware.CreateChildId("frontend", plan.ParentName.ValueString(), response.Name)
 	plan.ID = types.StringValue(id)
 	plan.Name = types.StringValue(response.Name)
 	plan.Address = types.StringValue(response.Address)
@@ -152,6 +153,8 @@ func (r *bindResource) Create(ctx context.Context, req resource.CreateRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	addReloadError(&resp.Diagnostics, "bind", retry_err)
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -165,10 +168,25 @@ func (r *bindResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	parentName, bindName, _ := middleware.ResourceParseId(ctx, state.ID.String())
+	parentName, bindName, err := middleware.ParseChildId(ctx, state.ID.String(), "frontend")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting resource ID",
+			"Could not read bind, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	ctx, reportDrift := withDriftWarnings(ctx)
+	defer reportDrift(&resp.Diagnostics)
 
 	// Get refreshed bind
-	response, err := r.client.GetBind(bindName, parentName)
+	response, err := r.client.GetBind(ctx, bindName, parentName)
+	if middleware.IsNotFound(err) {
+		// the bind was deleted outside of Terraform, plan its re-creation
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy Bind",
@@ -178,7 +196,7 @@ func (r *bindResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 
 	// Overwrite items with refreshed state
-	id := middleware.CreateResourceId(parentName, response.Name)
+	id := middleware.CreateChildId("frontend", parentName, response.Name)
 	state.ID = types.StringValue(id)
 	state.Name = types.StringValue(response.Name)
 	state.Address = types.StringValue(response.Address)
@@ -213,7 +231,7 @@ func (r *bindResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		Address: plan.Address.ValueString(),
 		Port:    plan.Port.ValueInt64(),
 	}
-	parentName, bindName, err := middleware.ResourceParseId(ctx, state.ID.String())
+	parentName, bindName, err := middleware.ParseChildId(ctx, state.ID.String(), "frontend")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting resource ID",
@@ -222,31 +240,21 @@ func (r *bindResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Update existing bind
-			_, err = r.client.UpdateBind(transaction.Id, payload, parentName)
-			if err != nil {
-				return err
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			_, err := r.client.UpdateBind(ctx, transactionId, payload, parentName)
 			if err != nil {
 				return err
 			}
 			return nil
 		})
+	})
 
-	if retry_err != nil {
+	// a failed reload leaves the bind committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error updating bind",
 			"Could not update bind, unexpected error: "+retry_err.Error(),
@@ -254,7 +262,7 @@ func (r *bindResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	response, err := r.client.GetBind(bindName, parentName)
+	response, err := r.client.GetBind(ctx, bindName, parentName)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy Bind",
@@ -264,7 +272,7 @@ func (r *bindResource) Update(ctx context.Context, req resource.UpdateRequest, r
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	id := middleware.CreateResourceId(parentName, response.Name)
+	id := middleware.CreateChildId("frontend", parentName, response.Name)
 	plan.ID = types.StringValue(id)
 	plan.Name = types.StringValue(response.Name)
 	plan.Address = types.StringValue(response.Address)
@@ -276,6 +284,7 @@ func (r *bindResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
+	addReloadError(&resp.Diagnostics, "bind", retry_err)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -289,31 +298,30 @@ func (r *bindResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	parentName, bindName, _ := middleware.ResourceParseId(ctx, state.ID.String())
+	parentName, bindName, err := middleware.ParseChildId(ctx, state.ID.String(), "frontend")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting resource ID",
+			"Could not delete bind, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	ctx = withReloadMode(ctx, state.ReloadMode)
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
-			// Delete existing bind
-			err = r.client.DeleteBind(transaction.Id, bindName, parentName)
-			if err != nil {
-				return err
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
+			// Delete existing bind, there is nothing to commit when it is already deleted
+			err := r.client.DeleteBind(ctx, transactionId, bindName, parentName)
+			if middleware.IsNotFound(err) {
+				return middleware.ErrNothingToCommit
 			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
 			if err != nil {
 				return err
 			}
 			return nil
 		})
+	})
 	if retry_err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting bind",
@@ -324,9 +332,66 @@ func (r *bindResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 
 }
 
+// ModifyPlan checks the sections referenced by the planned bind and validates
+// it when validate_on_plan is enabled.
+func (r *bindResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !checksReferences(r.client, req) {
+		return
+	}
+
+	// Retrieve values from plan
+	var plan bindResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkReferences(ctx, r.client, &resp.Diagnostics, frontendReference(r.client, "parent_name", plan.ParentName))
+	if resp.Diagnostics.HasError() || !validatesPlan(r.client, req) {
+		return
+	}
+
+	// generate api request payload
+	var payload = models.Bind{
+		Name:    plan.Name.ValueString(),
+		Address: plan.Address.ValueString(),
+		Port:    plan.Port.ValueInt64(),
+	}
+
+	validatePlan(ctx, r.client, func(ctx context.Context, transactionId string) error {
+		if createsObject(req, resp) {
+			_, err := r.client.CreateBind(ctx, transactionId, payload, plan.ParentName.ValueString())
+			return err
+		}
+		_, err := r.client.UpdateBind(ctx, transactionId, payload, plan.ParentName.ValueString())
+		return err
+	}, &resp.Diagnostics)
+}
+
+// UpgradeState migrates the states of the previous schema versions.
+func (r *bindResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// version 0 IDs were parent_name/name
+		0: upgradeState(schema.Schema{
+			Attributes: map[string]schema.Attribute{
+				"id":          schema.StringAttribute{Computed: true},
+				"name":        schema.StringAttribute{Optional: true},
+				"address":     schema.StringAttribute{Optional: true},
+				"port":        schema.Int64Attribute{Optional: true},
+				"parent_name": schema.StringAttribute{Optional: true},
+				"reload_mode": schema.StringAttribute{Optional: true},
+			},
+		}, func(ctx context.Context, state *bindResourceModel) {
+			state.ID = migrateChildId(state.ID, "frontend", state.ParentName, state.Name)
+		}),
+	}
+}
+
 func (r *bindResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	parentName, bindName, err := middleware.ResourceParseId(ctx, req.ID)
+	// Retrieve import ID and save to id attribute, Read migrates legacy
+	// parent_name/name IDs
+	parentName, bindName, err := middleware.ParseChildId(ctx, req.ID, "frontend")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error importing resource",
@@ -2,23 +2,28 @@ package haproxy
 
 import (
 	"context"
-	"terraform-provider-haproxy-pf/haproxy/middleware"
-	"terraform-provider-haproxy-pf/haproxy/models"
-
-	"github.com/avast/retry-go/v4"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+	"terraform-provider-haproxy-pf/haproxy/models"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &frontendResource{}
-	_ resource.ResourceWithConfigure   = &frontendResource{}
-	_ resource.ResourceWithImportState = &frontendResource{}
+	_ resource.Resource                 = &frontendResource{}
+	_ resource.ResourceWithConfigure    = &frontendResource{}
+	_ resource.ResourceWithImportState  = &frontendResource{}
+	_ resource.ResourceWithModifyPlan   = &frontendResource{}
+	_ resource.ResourceWithUpgradeState = &frontendResource{}
 )
 
 // NewFrontendResource is a helper function to simplify the provider implementation.
@@ -39,6 +44,7 @@ type frontendResourceModel struct {
 	Maxconn            types.Int64  `tfsdk:"maxconn"`
 	DefaultBackend     types.String `tfsdk:"default_backend"`
 	HTTPConnectionMode types.String `tfsdk:"http_connection_mode"`
+	ReloadMode         types.String `tfsdk:"reload_mode"`
 }
 
 // Metadata returns the resource type name.
@@ -49,9 +55,14 @@ func (r *frontendResource) Metadata(_ context.Context, req resource.MetadataRequ
 // Schema defines the schema for the resource.
 func (r *frontendResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		// bump with a new UpgradeState entry when the state changes
+		Version: 1,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"name": schema.StringAttribute{
 				Required: true,
@@ -59,32 +70,35 @@ func (r *frontendResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Description: "Name of the frontend, made of " + identifierCharacters + ".",
+				Validators:  []validator.String{identifierValidator()},
 			},
 			"mode": schema.StringAttribute{
-				Optional: true,
-				Computed: true,
-				PlanModifiers: []planmodifier.String{
-					middleware.StringDefaultValue(types.StringValue("http")),
-				},
+				Optional:    true,
+				Computed:    true,
+				Description: "http or tcp. Defaults to http, existing frontends without a mode keep it unset.",
+				Default:     stringdefault.StaticString("http"),
+				Validators:  []validator.String{stringvalidator.OneOf(proxyModes...)},
 			},
 			"maxconn": schema.Int64Attribute{
-				Optional: true,
-				Computed: true,
-				PlanModifiers: []planmodifier.Int64{
-					middleware.Int64DefaultValue(types.Int64Value(0)),
-				},
+				Optional:    true,
+				Computed:    true,
+				Description: "Defaults to 0.",
+				Default:     int64default.StaticInt64(0),
+				Validators:  []validator.Int64{int64validator.AtLeast(0)},
 			},
 			"default_backend": schema.StringAttribute{
-				Optional: true,
+				Optional:   true,
+				Validators: []validator.String{identifierValidator()},
 			},
 			"http_connection_mode": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
-				Description: "possible values: httpclose,http-server-close,http-keep-alive",
-				PlanModifiers: []planmodifier.String{
-					middleware.StringDefaultValue(types.StringValue("http-keep-alive")),
-				},
+				Description: "possible values: httpclose,http-server-close,http-keep-alive, or empty to leave the option unset. Defaults to http-keep-alive, existing frontends without the option keep it unset.",
+				Default:     stringdefault.StaticString("http-keep-alive"),
+				Validators:  []validator.String{stringvalidator.OneOf(append([]string{""}, httpConnectionModes...)...)},
 			},
+			"reload_mode": reloadModeAttribute,
 		},
 	}
 }
@@ -119,32 +133,21 @@ func (r *frontendResource) Create(ctx context.Context, req resource.CreateReques
 	}
 
 	var response *models.Frontend
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Create new frontend
-			create_response, err := r.client.CreateFrontend(transaction.Id, payload)
-			if err != nil {
-				return err
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			create_response, err := r.client.CreateFrontend(ctx, transactionId, payload)
 			if err != nil {
 				return err
 			}
 			response = create_response
 			return nil
-		},
-	)
-	if retry_err != nil {
+		})
+	})
+	// a failed reload leaves the frontend committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error creating frontend",
 			"Could not create frontend, unexpected error: "+retry_err.Error(),
@@ -166,6 +169,8 @@ func (r *frontendResource) Create(ctx context.Context, req resource.CreateReques
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	addReloadError(&resp.Diagnostics, "frontend", retry_err)
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -181,8 +186,16 @@ func (r *frontendResource) Read(ctx context.Context, req resource.ReadRequest, r
 
 	_, frontendName, _ := middleware.ResourceParseId(ctx, state.ID.String())
 
+	ctx, reportDrift := withDriftWarnings(ctx)
+	defer reportDrift(&resp.Diagnostics)
+
 	// Get refreshed frontend
-	response, err := r.client.GetFrontend(frontendName)
+	response, err := r.client.GetFrontend(ctx, frontendName)
+	if middleware.IsNotFound(err) {
+		// the frontend was deleted outside of Terraform, plan its re-creation
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy Frontend",
@@ -239,34 +252,21 @@ func (r *frontendResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
 
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Update existing frontend
-			_, err = r.client.UpdateFrontend(transaction.Id, frontendName, payload)
-			if err != nil {
-				return err
-			}
-
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			_, err := r.client.UpdateFrontend(ctx, transactionId, frontendName, payload)
 			if err != nil {
 				return err
 			}
 			return nil
-		},
-	)
+		})
+	})
 
-	if retry_err != nil {
+	// a failed reload leaves the frontend committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error updating frontend",
 			"Could not update frontend, unexpected error: "+retry_err.Error(),
@@ -276,7 +276,7 @@ func (r *frontendResource) Update(ctx context.Context, req resource.UpdateReques
 
 	// Fetch updated items from GetOrder as UpdateOrder items are not
 	// populated.
-	response, err := r.client.GetFrontend(frontendName)
+	response, err := r.client.GetFrontend(ctx, frontendName)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy Frontend",
@@ -299,6 +299,7 @@ func (r *frontendResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
+	addReloadError(&resp.Diagnostics, "frontend", retry_err)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -314,29 +315,21 @@ func (r *frontendResource) Delete(ctx context.Context, req resource.DeleteReques
 
 	_, frontendName, _ := middleware.ResourceParseId(ctx, state.ID.String())
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
-			// Delete existing frontend
-			err = r.client.DeleteFrontend(transaction.Id, frontendName)
-			if err != nil {
-				return err
+	ctx = withReloadMode(ctx, state.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
+			// Delete existing frontend, there is nothing to commit when it is already deleted
+			err := r.client.DeleteFrontend(ctx, transactionId, frontendName)
+			if middleware.IsNotFound(err) {
+				return middleware.ErrNothingToCommit
 			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
 			if err != nil {
 				return err
 			}
 			return nil
 		})
+	})
 	if retry_err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting frontend",
@@ -347,6 +340,83 @@ func (r *frontendResource) Delete(ctx context.Context, req resource.DeleteReques
 
 }
 
+// ModifyPlan checks the sections referenced by the planned frontend and validates
+// it when validate_on_plan is enabled.
+func (r *frontendResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.State.Raw.IsNull() && !req.Plan.Raw.IsNull() {
+		// frontends created before the defaults applied have these options
+		// unset, running in tcp mode: keep them rather than switching to http
+		keepUnsetString(ctx, req, resp, path.Root("mode"))
+		keepUnsetString(ctx, req, resp, path.Root("http_connection_mode"))
+	}
+	if resp.Diagnostics.HasError() || !checksReferences(r.client, req) {
+		return
+	}
+
+	// Retrieve values from plan
+	var plan frontendResourceModel
+	diags := resp.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkReferences(ctx, r.client, &resp.Diagnostics, backendReference(r.client, "default_backend", plan.DefaultBackend))
+	if resp.Diagnostics.HasError() || !validatesPlan(r.client, req) {
+		return
+	}
+
+	// generate api request payload
+	var payload = models.Frontend{
+		Name:               plan.Name.ValueString(),
+		Mode:               plan.Mode.ValueString(),
+		Maxconn:            plan.Maxconn.ValueInt64(),
+		DefaultBackend:     plan.DefaultBackend.ValueString(),
+		HTTPConnectionMode: plan.HTTPConnectionMode.ValueString(),
+	}
+
+	validatePlan(ctx, r.client, func(ctx context.Context, transactionId string) error {
+		if createsObject(req, resp) {
+			_, err := r.client.CreateFrontend(ctx, transactionId, payload)
+			return err
+		}
+		_, err := r.client.UpdateFrontend(ctx, transactionId, plan.Name.ValueString(), payload)
+		return err
+	}, &resp.Diagnostics)
+}
+
+// UpgradeState migrates the states of the previous schema versions.
+func (r *frontendResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// version 0 had no effective defaults: mode and http_connection_mode
+		// were stored as "" when unset, as HAProxy reports them. The values
+		// are kept as is, ModifyPlan keeping them unset on the next plans.
+		0: upgradeState[frontendResourceModel](schema.Schema{
+			Attributes: map[string]schema.Attribute{
+				"id":                   schema.StringAttribute{Computed: true},
+				"name":                 schema.StringAttribute{Optional: true},
+				"mode":                 schema.StringAttribute{Optional: true},
+				"maxconn":              schema.Int64Attribute{Optional: true},
+				"default_backend":      schema.StringAttribute{Optional: true},
+				"http_connection_mode": schema.StringAttribute{Optional: true},
+				"reload_mode":          schema.StringAttribute{Optional: true},
+			},
+		}, nil),
+	}
+}
+
+// keepUnsetString keeps the empty string value of the attribute at p in the
+// plan when it is omitted from the configuration, instead of its default.
+func keepUnsetString(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, p path.Path) {
+	var config, state types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, p, &config)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, p, &state)...)
+	if resp.Diagnostics.HasError() || !config.IsNull() || state.IsNull() || state.ValueString() != "" {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, p, state)...)
+}
+
 func (r *frontendResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Retrieve import ID and save to id attribute
 	_, frontendName, err := middleware.ResourceParseId(ctx, req.ID)
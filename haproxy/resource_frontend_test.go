@@ -105,3 +105,66 @@ func TestAccFrontendResource(t *testing.T) {
 		},
 	})
 }
+
+func TestAccFrontendResourceDefaults(t *testing.T) {
+	frontendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + fmt.Sprintf(`
+				resource "haproxy-pf_frontend" "%s" {
+					name = "%s"
+				}
+				`, frontendName, frontendName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_frontend.%s", frontendName), "mode", "http"),
+					resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_frontend.%s", frontendName), "maxconn", "0"),
+					resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_frontend.%s", frontendName), "http_connection_mode", "http-keep-alive"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccFrontendResourceUnsetMode(t *testing.T) {
+	server, config := newTestServer(t)
+	frontendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	// as created before the defaults applied
+	server.PutObject("frontends", "", map[string]interface{}{"name": frontendName})
+	frontendConfig := config + fmt.Sprintf(`
+	resource "haproxy-pf_frontend" "%s" {
+		name = "%s"
+	}
+	`, frontendName, frontendName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:             frontendConfig,
+				ResourceName:       fmt.Sprintf("haproxy-pf_frontend.%s", frontendName),
+				ImportState:        true,
+				ImportStateId:      "root/" + frontendName,
+				ImportStatePersist: true,
+			},
+			// the frontend keeps running in tcp mode
+			{
+				Config:   frontendConfig,
+				PlanOnly: true,
+			},
+			{
+				Config: config + fmt.Sprintf(`
+				resource "haproxy-pf_frontend" "%s" {
+					name = "%s"
+					mode = "http"
+				}
+				`, frontendName, frontendName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_frontend.%s", frontendName), "mode", "http"),
+					resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_frontend.%s", frontendName), "http_connection_mode", ""),
+				),
+			},
+		},
+	})
+}
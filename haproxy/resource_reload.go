@@ -0,0 +1,162 @@
+package haproxy
+
+import (
+	"context"
+	"time"
+
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource              = &reloadResource{}
+	_ resource.ResourceWithConfigure = &reloadResource{}
+)
+
+// reloadModeAttribute is the reload_mode attribute of the configuration resources.
+var reloadModeAttribute = schema.StringAttribute{
+	Optional:    true,
+	Description: "Reload behavior of the changes of this resource: \"default\", \"force\" to reload immediately or \"skip\" to leave the reload to a haproxy-pf_reload resource. Defaults to the provider reload_mode.",
+	Validators:  []validator.String{reloadModeValidator()},
+}
+
+// reloadModeValidator checks reload_mode values.
+func reloadModeValidator() validator.String {
+	return stringvalidator.OneOf(middleware.ReloadModeDefault, middleware.ReloadModeForce, middleware.ReloadModeSkip)
+}
+
+// withReloadMode returns a context committing changes with the reload mode
+// of a resource, the provider one when it is null.
+func withReloadMode(ctx context.Context, mode types.String) context.Context {
+	return middleware.WithReloadMode(ctx, mode.ValueString())
+}
+
+// addReloadError reports the error of a change committed without HAProxy
+// reloading it. The state of the change must be saved anyway, since the
+// object exists in the configuration.
+func addReloadError(diags *diag.Diagnostics, object string, err error) {
+	if !middleware.IsReloadError(err) {
+		return
+	}
+	diags.AddError(
+		"Haproxy reload failed",
+		"The "+object+" was committed to the haproxy configuration but HAProxy did not reload it: "+err.Error(),
+	)
+}
+
+// NewReloadResource is a helper function to simplify the provider implementation.
+func NewReloadResource() resource.Resource {
+	return &reloadResource{}
+}
+
+// reloadResource reloads HAProxy when created and when its triggers change.
+type reloadResource struct {
+	client *middleware.Client
+}
+
+// reloadResourceModel maps reload schema data.
+type reloadResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	Triggers types.Map    `tfsdk:"triggers"`
+}
+
+// Metadata returns the resource type name.
+func (r *reloadResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_reload"
+}
+
+// Schema defines the schema for the resource.
+func (r *reloadResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reloads HAProxy, applying the changes made with reload_mode \"skip\". The reload happens on creation and whenever triggers change.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"triggers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Arbitrary values, e.g. the ids of the resources written without reload, whose change triggers a reload.",
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *reloadResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.client = req.ProviderData.(*middleware.Client)
+}
+
+// Create reloads HAProxy and sets the initial Terraform state.
+func (r *reloadResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan reloadResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.reload(ctx); err != nil {
+		resp.Diagnostics.AddError(
+			"Error reloading haproxy",
+			"Could not reload haproxy, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	plan.ID = types.StringValue(time.Now().UTC().Format(time.RFC3339Nano))
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read keeps the state, a reload has nothing to refresh.
+func (r *reloadResource) Read(_ context.Context, _ resource.ReadRequest, _ *resource.ReadResponse) {
+}
+
+// Update reloads HAProxy when the triggers changed.
+func (r *reloadResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan reloadResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.reload(ctx); err != nil {
+		resp.Diagnostics.AddError(
+			"Error reloading haproxy",
+			"Could not reload haproxy, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the resource from the Terraform state, HAProxy is left as is.
+func (r *reloadResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+}
+
+func (r *reloadResource) reload(ctx context.Context) error {
+	return r.client.Retry(ctx, func() error {
+		return r.client.Reload(ctx)
+	})
+}
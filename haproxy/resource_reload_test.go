@@ -0,0 +1,73 @@
+package haproxy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccReloadResource(t *testing.T) {
+	server, skipReloadConfig := newTestServer(t, `reload_mode = "skip"`)
+
+	checkReloads := func(want int) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			if reloads := server.Reloads(); len(reloads) != want {
+				return fmt.Errorf("got reloads %v, want %d", reloads, want)
+			}
+			return nil
+		}
+	}
+
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// changes are only written
+			{
+				Config: skipReloadConfig + fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "roundrobin"
+					mode = "http"
+				}
+				`, backendName, backendName),
+				Check: checkReloads(0),
+			},
+			// the reload resource applies them
+			{
+				Config: skipReloadConfig + fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "roundrobin"
+					mode = "tcp"
+				}
+
+				resource "haproxy-pf_reload" "%s" {
+					triggers = {
+						mode = haproxy-pf_backend.%s.mode
+					}
+				}
+				`, backendName, backendName, backendName, backendName),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet(fmt.Sprintf("haproxy-pf_reload.%s", backendName), "id"),
+					checkReloads(1),
+				),
+			},
+			// a resource can override the provider reload mode
+			{
+				Config: skipReloadConfig + fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "roundrobin"
+					mode = "http"
+					reload_mode = "force"
+				}
+				`, backendName, backendName),
+				Check: checkReloads(2),
+			},
+		},
+	})
+}
@@ -5,21 +5,23 @@ import (
 	"terraform-provider-haproxy-pf/haproxy/middleware"
 	"terraform-provider-haproxy-pf/haproxy/models"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-
-	"github.com/avast/retry-go/v4"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &serverResource{}
-	_ resource.ResourceWithConfigure   = &serverResource{}
-	_ resource.ResourceWithImportState = &serverResource{}
+	_ resource.Resource                 = &serverResource{}
+	_ resource.ResourceWithConfigure    = &serverResource{}
+	_ resource.ResourceWithImportState  = &serverResource{}
+	_ resource.ResourceWithModifyPlan   = &serverResource{}
+	_ resource.ResourceWithUpgradeState = &serverResource{}
 )
 
 // NewServerResource is a helper function to simplify the provider implementation.
@@ -40,6 +42,7 @@ type serverResourceModel struct {
 	Check      types.String `tfsdk:"check"`
 	Port       types.Int64  `tfsdk:"port"`
 	ParentName types.String `tfsdk:"parent_name"`
+	ReloadMode types.String `tfsdk:"reload_mode"`
 }
 
 // Metadata returns the resource type name.
@@ -50,9 +53,12 @@ func (r *serverResource) Metadata(_ context.Context, req resource.MetadataReques
 // Schema defines the schema for the resource.
 func (r *serverResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		// bump with a new UpgradeState entry when the state changes
+		Version: 1,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Computed: true,
+				Computed:    true,
+				Description: "backend/<parent_name>/<name>, with the names path escaped.",
 			},
 			"name": schema.StringAttribute{
 				Required: true,
@@ -60,18 +66,24 @@ func (r *serverResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Description: "Name of the server, made of " + serverNameCharacters + ".",
+				Validators:  []validator.String{serverNameValidator()},
 			},
 			"address": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required:   true,
+				Optional:   false,
+				Validators: []validator.String{addressValidator{allowWildcard: false}},
 			},
 			"check": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required:    true,
+				Optional:    false,
+				Description: "enabled or disabled.",
+				Validators:  []validator.String{stringvalidator.OneOf(checkValues...)},
 			},
 			"port": schema.Int64Attribute{
-				Required: true,
-				Optional: false,
+				Required:   true,
+				Optional:   false,
+				Validators: []validator.Int64{portValidator()},
 			},
 			"parent_name": schema.StringAttribute{
 				Required: true,
@@ -79,7 +91,9 @@ func (r *serverResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{identifierValidator()},
 			},
+			"reload_mode": reloadModeAttribute,
 		},
 	}
 }
@@ -113,32 +127,21 @@ func (r *serverResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	var response *models.Server
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Create new server
-			create_response, err := r.client.CreateServer(transaction.Id, payload, plan.ParentName.ValueString())
-			if err != nil {
-				return err
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			create_response, err := r.client.CreateServer(ctx, transactionId, payload, plan.ParentName.ValueString())
 			if err != nil {
 				return err
 			}
 			response = create_response
 			return nil
-		},
-	)
-	if retry_err != nil {
+		})
+	})
+	// a failed reload leaves the server committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error creating server",
 			"Could not create server, unexpected error: "+retry_err.Error(),
@@ -147,7 +150,7 @@ func (r *serverResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	id := middleware.CreateResourceId(plan.ParentName.ValueString(), response.Name)
+	id := middleware.CreateChildId("backend", plan.ParentName.ValueString(), response.Name)
 	plan.ID = types.StringValue(id)
 	plan.Name = types.StringValue(response.Name)
 	plan.Address = types.StringValue(response.Address)
@@ -160,6 +163,8 @@ func (r *serverResource) Create(ctx context.Context, req resource.CreateRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	addReloadError(&resp.Diagnostics, "server", retry_err)
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -173,10 +178,25 @@ func (r *serverResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	parentName, serverName, _ := middleware.ResourceParseId(ctx, state.ID.String())
+	parentName, serverName, err := middleware.ParseChildId(ctx, state.ID.String(), "backend")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting resource ID",
+			"Could not read server, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	ctx, reportDrift := withDriftWarnings(ctx)
+	defer reportDrift(&resp.Diagnostics)
 
 	// Get refreshed server
-	response, err := r.client.GetServer(serverName, parentName)
+	response, err := r.client.GetServer(ctx, serverName, parentName)
+	if middleware.IsNotFound(err) {
+		// the server was deleted outside of Terraform, plan its re-creation
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy Server",
@@ -186,7 +206,7 @@ func (r *serverResource) Read(ctx context.Context, req resource.ReadRequest, res
 	}
 
 	// Overwrite items with refreshed state
-	id := middleware.CreateResourceId(parentName, response.Name)
+	id := middleware.CreateChildId("backend", parentName, response.Name)
 	state.ID = types.StringValue(id)
 	state.Name = types.StringValue(response.Name)
 	state.Address = types.StringValue(response.Address)
@@ -223,7 +243,7 @@ func (r *serverResource) Update(ctx context.Context, req resource.UpdateRequest,
 		Port:    plan.Port.ValueInt64(),
 		Check:   plan.Check.ValueString(),
 	}
-	parentName, serverName, err := middleware.ResourceParseId(ctx, state.ID.String())
+	parentName, serverName, err := middleware.ParseChildId(ctx, state.ID.String(), "backend")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting resource ID",
@@ -232,30 +252,20 @@ func (r *serverResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Update existing server
-			_, err = r.client.UpdateServer(transaction.Id, payload, parentName)
-			if err != nil {
-				return err
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			_, err := r.client.UpdateServer(ctx, transactionId, payload, parentName)
 			if err != nil {
 				return err
 			}
 			return nil
 		})
-	if retry_err != nil {
+	})
+	// a failed reload leaves the server committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error updating server",
 			"Could not update server, unexpected error: "+retry_err.Error(),
@@ -263,7 +273,7 @@ func (r *serverResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	response, err := r.client.GetServer(serverName, parentName)
+	response, err := r.client.GetServer(ctx, serverName, parentName)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy Server",
@@ -273,7 +283,7 @@ func (r *serverResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	id := middleware.CreateResourceId(parentName, response.Name)
+	id := middleware.CreateChildId("backend", parentName, response.Name)
 	plan.ID = types.StringValue(id)
 	plan.Name = types.StringValue(response.Name)
 	plan.Address = types.StringValue(response.Address)
@@ -286,6 +296,7 @@ func (r *serverResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	addReloadError(&resp.Diagnostics, "server", retry_err)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -299,31 +310,30 @@ func (r *serverResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	parentName, serverName, _ := middleware.ResourceParseId(ctx, state.ID.String())
+	parentName, serverName, err := middleware.ParseChildId(ctx, state.ID.String(), "backend")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting resource ID",
+			"Could not delete server, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	ctx = withReloadMode(ctx, state.ReloadMode)
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
+			// Delete existing server, there is nothing to commit when it is already deleted
+			err := r.client.DeleteServer(ctx, transactionId, serverName, parentName)
+			if middleware.IsNotFound(err) {
+				return middleware.ErrNothingToCommit
 			}
-			// Delete existing server
-			err = r.client.DeleteServer(transaction.Id, serverName, parentName)
-			if err != nil {
-				return err
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
 			if err != nil {
 				return err
 			}
 			return nil
 		})
+	})
 	if retry_err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting server",
@@ -333,9 +343,68 @@ func (r *serverResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	}
 }
 
+// ModifyPlan checks the sections referenced by the planned server and validates
+// it when validate_on_plan is enabled.
+func (r *serverResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !checksReferences(r.client, req) {
+		return
+	}
+
+	// Retrieve values from plan
+	var plan serverResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkReferences(ctx, r.client, &resp.Diagnostics, backendReference(r.client, "parent_name", plan.ParentName))
+	if resp.Diagnostics.HasError() || !validatesPlan(r.client, req) {
+		return
+	}
+
+	// generate api request payload
+	var payload = models.Server{
+		Name:    plan.Name.ValueString(),
+		Address: plan.Address.ValueString(),
+		Port:    plan.Port.ValueInt64(),
+		Check:   plan.Check.ValueString(),
+	}
+
+	validatePlan(ctx, r.client, func(ctx context.Context, transactionId string) error {
+		if createsObject(req, resp) {
+			_, err := r.client.CreateServer(ctx, transactionId, payload, plan.ParentName.ValueString())
+			return err
+		}
+		_, err := r.client.UpdateServer(ctx, transactionId, payload, plan.ParentName.ValueString())
+		return err
+	}, &resp.Diagnostics)
+}
+
+// UpgradeState migrates the states of the previous schema versions.
+func (r *serverResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// version 0 IDs were parent_name/name
+		0: upgradeState(schema.Schema{
+			Attributes: map[string]schema.Attribute{
+				"id":          schema.StringAttribute{Computed: true},
+				"name":        schema.StringAttribute{Optional: true},
+				"address":     schema.StringAttribute{Optional: true},
+				"check":       schema.StringAttribute{Optional: true},
+				"port":        schema.Int64Attribute{Optional: true},
+				"parent_name": schema.StringAttribute{Optional: true},
+				"reload_mode": schema.StringAttribute{Optional: true},
+			},
+		}, func(ctx context.Context, state *serverResourceModel) {
+			state.ID = migrateChildId(state.ID, "backend", state.ParentName, state.Name)
+		}),
+	}
+}
+
 func (r *serverResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	parentName, serverName, err := middleware.ResourceParseId(ctx, req.ID)
+	// Retrieve import ID and save to id attribute, Read migrates legacy
+	// parent_name/name IDs
+	parentName, serverName, err := middleware.ParseChildId(ctx, req.ID, "backend")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error importing resource",
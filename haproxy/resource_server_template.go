@@ -5,21 +5,23 @@ import (
 	"terraform-provider-haproxy-pf/haproxy/middleware"
 	"terraform-provider-haproxy-pf/haproxy/models"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-
-	"github.com/avast/retry-go/v4"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &serverTemplateResource{}
-	_ resource.ResourceWithConfigure   = &serverTemplateResource{}
-	_ resource.ResourceWithImportState = &serverTemplateResource{}
+	_ resource.Resource                 = &serverTemplateResource{}
+	_ resource.ResourceWithConfigure    = &serverTemplateResource{}
+	_ resource.ResourceWithImportState  = &serverTemplateResource{}
+	_ resource.ResourceWithModifyPlan   = &serverTemplateResource{}
+	_ resource.ResourceWithUpgradeState = &serverTemplateResource{}
 )
 
 // NewServerTemplateResource is a helper function to simplify the provider implementation.
@@ -43,6 +45,7 @@ type serverTemplateResourceModel struct {
 	Check            types.String `tfsdk:"check"`
 	Resolvers        types.String `tfsdk:"resolvers"`
 	ParentName       types.String `tfsdk:"parent_name"`
+	ReloadMode       types.String `tfsdk:"reload_mode"`
 
 }
 
@@ -54,24 +57,34 @@ func (r *serverTemplateResource) Metadata(_ context.Context, req resource.Metada
 // Schema defines the schema for the resource.
 func (r *serverTemplateResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		// bump with a new UpgradeState entry when the state changes
+		Version: 1,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Computed: true,
+				Computed:    true,
+				Description: "backend/<parent_name>/<prefix>, with the names path escaped.",
 			},
 			"name": schema.StringAttribute{
 				Computed: true,
 			},
 			"fqdn": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required:    true,
+				Optional:    false,
+				Description: "DNS name resolved by the resolvers, e.g. \"backend.example.com\" or the SRV record \"_http._tcp.service.consul\".",
+				Validators:  []validator.String{dnsNameValidator()},
 			},
 			"num_or_range": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required:    true,
+				Optional:    false,
+				Description: "Number of servers, e.g. \"3\", or range of their numbers, e.g. \"1-3\".",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(numOrRangeRegexp, "must be a number or a range of numbers"),
+				},
 			},
 			"port": schema.Int64Attribute{
-				Required: true,
-				Optional: false,
+				Required:   true,
+				Optional:   false,
+				Validators: []validator.Int64{portValidator()},
 			},
 			"prefix": schema.StringAttribute{
 				Required: true,
@@ -79,14 +92,19 @@ func (r *serverTemplateResource) Schema(_ context.Context, _ resource.SchemaRequ
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Description: "Prefix of the server names, made of " + serverNameCharacters + ".",
+				Validators:  []validator.String{serverNameValidator()},
 			},
 			"check": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required:    true,
+				Optional:    false,
+				Description: "enabled or disabled.",
+				Validators:  []validator.String{stringvalidator.OneOf(checkValues...)},
 			},
 			"resolvers": schema.StringAttribute{
-				Required: true,
-				Optional: false,
+				Required:   true,
+				Optional:   false,
+				Validators: []validator.String{identifierValidator()},
 			},
 			"parent_name": schema.StringAttribute{
 				Required: true,
@@ -94,7 +112,9 @@ func (r *serverTemplateResource) Schema(_ context.Context, _ resource.SchemaRequ
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{identifierValidator()},
 			},
+			"reload_mode": reloadModeAttribute,
 		},
 	}
 }
@@ -130,32 +150,21 @@ func (r *serverTemplateResource) Create(ctx context.Context, req resource.Create
 	}
 
 	var response *models.ServerTemplate
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Create new serverTemplate
-			create_response, err := r.client.CreateServerTemplate(transaction.Id, payload, plan.ParentName.ValueString())
-			if err != nil {
-				return err
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			create_response, err := r.client.CreateServerTemplate(ctx, transactionId, payload, plan.ParentName.ValueString())
 			if err != nil {
 				return err
 			}
 			response = create_response
 			return nil
-		},
-	)
-	if retry_err != nil {
+		})
+	})
+	// a failed reload leaves the server template committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error creating serverTemplate",
 			"Could not create serverTemplate, unexpected error: "+retry_err.Error(),
@@ -164,7 +173,7 @@ func (r *serverTemplateResource) Create(ctx context.Context, req resource.Create
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	id := middleware.CreateResourceId(plan.ParentName.ValueString(), response.Prefix)
+	id := middleware.CreateChildId("backend", plan.ParentName.ValueString(), response.Prefix)
 	plan.ID = types.StringValue(id)
 	plan.Name = types.StringValue(response.Prefix)
 	plan.Fqdn = types.StringValue(response.Fqdn)
@@ -180,6 +189,8 @@ func (r *serverTemplateResource) Create(ctx context.Context, req resource.Create
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	addReloadError(&resp.Diagnostics, "server template", retry_err)
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -193,10 +204,25 @@ func (r *serverTemplateResource) Read(ctx context.Context, req resource.ReadRequ
 		return
 	}
 
-	parentName, serverTemplateName, _ := middleware.ResourceParseId(ctx, state.ID.String())
+	parentName, serverTemplateName, err := middleware.ParseChildId(ctx, state.ID.String(), "backend")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting resource ID",
+			"Could not read serverTemplate, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	ctx, reportDrift := withDriftWarnings(ctx)
+	defer reportDrift(&resp.Diagnostics)
 
 	// Get refreshed serverTemplate
-	response, err := r.client.GetServerTemplate(serverTemplateName, parentName)
+	response, err := r.client.GetServerTemplate(ctx, serverTemplateName, parentName)
+	if middleware.IsNotFound(err) {
+		// the server template was deleted outside of Terraform, plan its re-creation
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy ServerTemplate",
@@ -206,7 +232,7 @@ func (r *serverTemplateResource) Read(ctx context.Context, req resource.ReadRequ
 	}
 
 	// Overwrite items with refreshed state
-	id := middleware.CreateResourceId(parentName, response.Prefix)
+	id := middleware.CreateChildId("backend", parentName, response.Prefix)
 	state.ID = types.StringValue(id)
 	state.Name = types.StringValue(response.Prefix)
 	state.Fqdn = types.StringValue(response.Fqdn)
@@ -248,7 +274,7 @@ func (r *serverTemplateResource) Update(ctx context.Context, req resource.Update
 		Check: plan.Check.ValueString(),
 		Resolvers: plan.Resolvers.ValueString(),
 	}
-	parentName, serverTemplateName, err := middleware.ResourceParseId(ctx, state.ID.String())
+	parentName, serverTemplateName, err := middleware.ParseChildId(ctx, state.ID.String(), "backend")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error getting resource ID",
@@ -257,30 +283,20 @@ func (r *serverTemplateResource) Update(ctx context.Context, req resource.Update
 		return
 	}
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
+	ctx = withReloadMode(ctx, plan.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
 			// Update existing serverTemplate
-			_, err = r.client.UpdateServerTemplate(transaction.Id, payload, parentName)
-			if err != nil {
-				return err
-			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
+			_, err := r.client.UpdateServerTemplate(ctx, transactionId, payload, parentName)
 			if err != nil {
 				return err
 			}
 			return nil
 		})
-	if retry_err != nil {
+	})
+	// a failed reload leaves the server template committed, its state is saved below
+	if retry_err != nil && !middleware.IsReloadError(retry_err) {
 		resp.Diagnostics.AddError(
 			"Error updating serverTemplate",
 			"Could not update serverTemplate, unexpected error: "+retry_err.Error(),
@@ -288,7 +304,7 @@ func (r *serverTemplateResource) Update(ctx context.Context, req resource.Update
 		return
 	}
 
-	response, err := r.client.GetServerTemplate(serverTemplateName, parentName)
+	response, err := r.client.GetServerTemplate(ctx, serverTemplateName, parentName)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Haproxy ServerTemplate",
@@ -298,7 +314,7 @@ func (r *serverTemplateResource) Update(ctx context.Context, req resource.Update
 	}
 
 	// Map response body to schema and populate Computed attribute values
-	id := middleware.CreateResourceId(parentName, response.Prefix)
+	id := middleware.CreateChildId("backend", parentName, response.Prefix)
 	plan.ID = types.StringValue(id)
 	plan.Name = types.StringValue(response.Prefix)
 	plan.Fqdn = types.StringValue(response.Fqdn)
@@ -315,6 +331,7 @@ func (r *serverTemplateResource) Update(ctx context.Context, req resource.Update
 		return
 	}
 
+	addReloadError(&resp.Diagnostics, "server template", retry_err)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -328,31 +345,30 @@ func (r *serverTemplateResource) Delete(ctx context.Context, req resource.Delete
 		return
 	}
 
-	parentName, serverTemplateName, _ := middleware.ResourceParseId(ctx, state.ID.String())
+	parentName, serverTemplateName, err := middleware.ParseChildId(ctx, state.ID.String(), "backend")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting resource ID",
+			"Could not delete serverTemplate, unexpected error: "+err.Error(),
+		)
+		return
+	}
 
-	retry_err := retry.Do(
-		func() error {
-			// Open transaction
-			configuration, err := r.client.GetConfiguration()
-			if err != nil {
-				return err
-			}
-			transaction, err := r.client.CreateTransaction(configuration.Version)
-			if err != nil {
-				return err
-			}
-			// Delete existing serverTemplate
-			err = r.client.DeleteServerTemplate(transaction.Id, serverTemplateName, parentName)
-			if err != nil {
-				return err
+	ctx = withReloadMode(ctx, state.ReloadMode)
+
+	retry_err := r.client.Retry(ctx, func() error {
+		return r.client.WithTransaction(ctx, func(ctx context.Context, transactionId string) error {
+			// Delete existing serverTemplate, there is nothing to commit when it is already deleted
+			err := r.client.DeleteServerTemplate(ctx, transactionId, serverTemplateName, parentName)
+			if middleware.IsNotFound(err) {
+				return middleware.ErrNothingToCommit
 			}
-			// commit transaction
-			_, err = r.client.CommitTransaction(transaction.Id)
 			if err != nil {
 				return err
 			}
 			return nil
 		})
+	})
 	if retry_err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting serverTemplate",
@@ -362,9 +378,76 @@ func (r *serverTemplateResource) Delete(ctx context.Context, req resource.Delete
 	}
 }
 
+// ModifyPlan checks the sections referenced by the planned server template and validates
+// it when validate_on_plan is enabled.
+func (r *serverTemplateResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !checksReferences(r.client, req) {
+		return
+	}
+
+	// Retrieve values from plan
+	var plan serverTemplateResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkReferences(ctx, r.client, &resp.Diagnostics,
+		backendReference(r.client, "parent_name", plan.ParentName),
+		resolversReference(r.client, "resolvers", plan.Resolvers),
+	)
+	if resp.Diagnostics.HasError() || !validatesPlan(r.client, req) {
+		return
+	}
+
+	// generate api request payload
+	var payload = models.ServerTemplate{
+		Fqdn:         plan.Fqdn.ValueString(),
+		Num_or_range: plan.Num_or_range.ValueString(),
+		Port:         plan.Port.ValueInt64(),
+		Prefix:       plan.Prefix.ValueString(),
+		Check:        plan.Check.ValueString(),
+		Resolvers:    plan.Resolvers.ValueString(),
+	}
+
+	validatePlan(ctx, r.client, func(ctx context.Context, transactionId string) error {
+		if createsObject(req, resp) {
+			_, err := r.client.CreateServerTemplate(ctx, transactionId, payload, plan.ParentName.ValueString())
+			return err
+		}
+		_, err := r.client.UpdateServerTemplate(ctx, transactionId, payload, plan.ParentName.ValueString())
+		return err
+	}, &resp.Diagnostics)
+}
+
+// UpgradeState migrates the states of the previous schema versions.
+func (r *serverTemplateResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// version 0 IDs were parent_name/name
+		0: upgradeState(schema.Schema{
+			Attributes: map[string]schema.Attribute{
+				"id":           schema.StringAttribute{Computed: true},
+				"name":         schema.StringAttribute{Optional: true},
+				"fqdn":         schema.StringAttribute{Optional: true},
+				"num_or_range": schema.StringAttribute{Optional: true},
+				"port":         schema.Int64Attribute{Optional: true},
+				"prefix":       schema.StringAttribute{Optional: true},
+				"check":        schema.StringAttribute{Optional: true},
+				"resolvers":    schema.StringAttribute{Optional: true},
+				"parent_name":  schema.StringAttribute{Optional: true},
+				"reload_mode":  schema.StringAttribute{Optional: true},
+			},
+		}, func(ctx context.Context, state *serverTemplateResourceModel) {
+			state.ID = migrateChildId(state.ID, "backend", state.ParentName, state.Prefix)
+		}),
+	}
+}
+
 func (r *serverTemplateResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	parentName, serverTemplateName, err := middleware.ResourceParseId(ctx, req.ID)
+	// Retrieve import ID and save to id attribute, Read migrates legacy
+	// parent_name/name IDs
+	parentName, serverTemplateName, err := middleware.ParseChildId(ctx, req.ID, "backend")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error importing resource",
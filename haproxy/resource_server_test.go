@@ -1,11 +1,18 @@
 package haproxy
 
 import (
+	"context"
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 func TestAccServerResource(t *testing.T) {
@@ -118,3 +125,141 @@ func TestAccServerResource(t *testing.T) {
 		},
 	})
 }
+
+func TestAccServerResourceDeletedOutOfBand(t *testing.T) {
+	server, config := newTestServer(t)
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	serverName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	serverConfig := config + fmt.Sprintf(`
+	resource "haproxy-pf_backend" "%s" {
+		name = "%s"
+		balance = "roundrobin"
+		mode = "http"
+	}
+
+	resource "haproxy-pf_server" "%s" {
+		name = "%s"
+		address = "10.0.0.1"
+		port = 80
+		check = "enabled"
+		parent_name = haproxy-pf_backend.%s.name
+	}
+	`, backendName, backendName, serverName, serverName, backendName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: serverConfig,
+			},
+			// a server deleted by hand is created again
+			{
+				PreConfig: func() { server.DeleteObject("servers", backendName, serverName) },
+				Config:    serverConfig,
+				Check: func(*terraform.State) error {
+					if _, ok := server.Object("servers", backendName, serverName); !ok {
+						return fmt.Errorf("server %s was not created again", serverName)
+					}
+					return nil
+				},
+			},
+			// destroying servers whose backend was deleted by hand succeeds
+			{
+				PreConfig: func() { server.DeleteObject("backends", "", backendName) },
+				Config:    serverConfig,
+				Destroy:   true,
+			},
+		},
+	})
+}
+
+func TestAccServerResourceIds(t *testing.T) {
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	serverName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha) + "/1"
+	legacyName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	resourceName := fmt.Sprintf("haproxy-pf_server.%s", backendName)
+	legacyResourceName := "haproxy-pf_server.legacy"
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + fmt.Sprintf(`
+				resource "haproxy-pf_backend" "%s" {
+					name = "%s"
+					balance = "roundrobin"
+					mode = "http"
+				}
+
+				resource "haproxy-pf_server" "%s" {
+					name = "%s"
+					address = "10.0.0.1"
+					port = 80
+					check = "enabled"
+					parent_name = haproxy-pf_backend.%s.name
+				}
+
+				resource "haproxy-pf_server" "legacy" {
+					name = "%s"
+					address = "10.0.0.2"
+					port = 80
+					check = "enabled"
+					parent_name = haproxy-pf_backend.%s.name
+				}
+				`, backendName, backendName, backendName, serverName, backendName, legacyName, backendName),
+				Check: resource.TestCheckResourceAttr(resourceName, "id", "backend/"+backendName+"/"+strings.ReplaceAll(serverName, "/", "%2F")),
+			},
+			// legacy IDs are migrated
+			{
+				ResourceName:      legacyResourceName,
+				ImportState:       true,
+				ImportStateId:     backendName + "/" + legacyName,
+				ImportStateVerify: true,
+			},
+			// legacy IDs are not split at a guessed slash
+			{
+				ResourceName:  resourceName,
+				ImportState:   true,
+				ImportStateId: backendName + "/" + serverName,
+				ExpectError:   regexp.MustCompile("ambiguous ID"),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestServerResourceInvalidId(t *testing.T) {
+	ctx := context.Background()
+	r := &serverResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	state := tfsdk.State{Schema: schemaResp.Schema}
+	if diags := state.Set(ctx, serverResourceModel{
+		ID:         types.StringValue("be_web/srv/1"),
+		Name:       types.StringValue("srv/1"),
+		Address:    types.StringValue("10.0.0.1"),
+		Check:      types.StringValue("enabled"),
+		Port:       types.Int64Value(80),
+		ParentName: types.StringValue("be_web"),
+		ReloadMode: types.StringNull(),
+	}); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	// the ambiguous ID is reported before any request
+	readResp := fwresource.ReadResponse{State: state}
+	r.Read(ctx, fwresource.ReadRequest{State: state}, &readResp)
+	if !readResp.Diagnostics.HasError() || readResp.State.Raw.IsNull() {
+		t.Errorf("expected an error keeping the state on read, got %v", readResp.Diagnostics)
+	}
+
+	deleteResp := fwresource.DeleteResponse{State: state}
+	r.Delete(ctx, fwresource.DeleteRequest{State: state}, &deleteResp)
+	if !deleteResp.Diagnostics.HasError() {
+		t.Error("expected an error on delete")
+	}
+}
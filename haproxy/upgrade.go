@@ -0,0 +1,46 @@
+package haproxy
+
+import (
+	"context"
+
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// upgradeState returns a state upgrader decoding the states of priorSchema
+// into the model T, changed by migrate when it is not nil. A schema change
+// only altering the meaning of values reuses the current model, one changing
+// attribute types decodes into a model frozen with priorSchema.
+func upgradeState[T any](priorSchema schema.Schema, migrate func(ctx context.Context, state *T)) resource.StateUpgrader {
+	return resource.StateUpgrader{
+		PriorSchema: &priorSchema,
+		StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+			var state T
+			resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			if migrate != nil {
+				migrate(ctx, &state)
+			}
+
+			resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+		},
+	}
+}
+
+// migrateChildId converts the legacy parent_name/name ID of an object of a
+// parentType section to the ID of middleware.CreateChildId. The ID is built
+// from the parent_name and name attributes of the state, legacy IDs being
+// ambiguous when a name contains a slash.
+func migrateChildId(id types.String, parentType string, parentName types.String, name types.String) types.String {
+	if parentName.ValueString() == "" || name.ValueString() == "" {
+		// left for Read to report
+		return id
+	}
+	return types.StringValue(middleware.CreateChildId(parentType, parentName.ValueString(), name.ValueString()))
+}
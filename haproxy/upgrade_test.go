@@ -0,0 +1,109 @@
+package haproxy
+
+import (
+	"context"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestServerResourceUpgradeState(t *testing.T) {
+	ctx := context.Background()
+	r := &serverResource{}
+
+	var current fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &current)
+	upgrader, ok := r.UpgradeState(ctx)[current.Schema.Version-1]
+	if !ok {
+		t.Fatalf("missing state upgrader from version %d", current.Schema.Version-1)
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		parentName string
+		serverName string
+		wantId     string
+	}{
+		{name: "legacy id", id: "be_web/srv1", parentName: "be_web", serverName: "srv1", wantId: "backend/be_web/srv1"},
+		{name: "current id", id: "backend/be_web/srv1", parentName: "be_web", serverName: "srv1", wantId: "backend/be_web/srv1"},
+		{name: "legacy id with unescaped names", id: "be web/srv1", parentName: "be web", serverName: "srv1", wantId: "backend/be%20web/srv1"},
+		// the legacy id alone could also be the server 1 of be_web/srv
+		{name: "legacy id with slashes", id: "be_web/srv/1", parentName: "be_web", serverName: "srv/1", wantId: "backend/be_web/srv%2F1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prior := tfsdk.State{Schema: *upgrader.PriorSchema}
+			if diags := prior.Set(ctx, serverResourceModel{
+				ID:         types.StringValue(tt.id),
+				Name:       types.StringValue(tt.serverName),
+				Address:    types.StringValue("10.0.0.1"),
+				Check:      types.StringValue("enabled"),
+				Port:       types.Int64Value(80),
+				ParentName: types.StringValue(tt.parentName),
+				ReloadMode: types.StringNull(),
+			}); diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+
+			req := fwresource.UpgradeStateRequest{State: &prior}
+			resp := fwresource.UpgradeStateResponse{State: tfsdk.State{Schema: current.Schema}}
+			upgrader.StateUpgrader(ctx, req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+
+			var state serverResourceModel
+			if diags := resp.State.Get(ctx, &state); diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if state.ID.ValueString() != tt.wantId || state.Port.ValueInt64() != 80 {
+				t.Errorf("unexpected upgraded state %+v", state)
+			}
+		})
+	}
+}
+
+func TestFrontendResourceUpgradeState(t *testing.T) {
+	ctx := context.Background()
+	r := &frontendResource{}
+
+	var current fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &current)
+	upgrader, ok := r.UpgradeState(ctx)[current.Schema.Version-1]
+	if !ok {
+		t.Fatalf("missing state upgrader from version %d", current.Schema.Version-1)
+	}
+
+	// version 0 stored the unset options as reported by HAProxy
+	prior := tfsdk.State{Schema: *upgrader.PriorSchema}
+	if diags := prior.Set(ctx, frontendResourceModel{
+		ID:                 types.StringValue("root/fe_tcp"),
+		Name:               types.StringValue("fe_tcp"),
+		Mode:               types.StringValue(""),
+		Maxconn:            types.Int64Value(0),
+		DefaultBackend:     types.StringNull(),
+		HTTPConnectionMode: types.StringValue(""),
+		ReloadMode:         types.StringNull(),
+	}); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	req := fwresource.UpgradeStateRequest{State: &prior}
+	resp := fwresource.UpgradeStateResponse{State: tfsdk.State{Schema: current.Schema}}
+	upgrader.StateUpgrader(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+
+	var state frontendResourceModel
+	if diags := resp.State.Get(ctx, &state); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if state.ID.ValueString() != "root/fe_tcp" || !state.Mode.Equal(types.StringValue("")) || !state.HTTPConnectionMode.Equal(types.StringValue("")) {
+		t.Errorf("unexpected upgraded state %+v", state)
+	}
+}
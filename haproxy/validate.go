@@ -0,0 +1,47 @@
+package haproxy
+
+import (
+	"context"
+
+	"terraform-provider-haproxy-pf/haproxy/middleware"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// validatesPlan reports whether the plan of req must be validated with
+// validatePlan: validate_on_plan is enabled and the change can be staged.
+func validatesPlan(client *middleware.Client, req resource.ModifyPlanRequest) bool {
+	// the provider is not configured during validation, destroys and
+	// unchanged resources have nothing to validate and unknown values
+	// cannot be staged
+	return client != nil && client.ValidatesOnPlan() && !req.Plan.Raw.IsNull() &&
+		!req.Plan.Raw.Equal(req.State.Raw) && req.Config.Raw.IsFullyKnown()
+}
+
+// createsObject reports whether a planned change creates its object, either
+// as a new resource or as the replacement of an existing one.
+func createsObject(req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) bool {
+	return req.State.Raw.IsNull() || len(resp.RequiresReplace) > 0
+}
+
+// validatePlan stages a planned change with stage in a throwaway transaction
+// and reports the errors HAProxy finds in the resulting configuration.
+// Changes that cannot be staged yet, e.g. on a parent created by the same
+// plan, are only reported as warnings.
+func validatePlan(ctx context.Context, client *middleware.Client, stage func(ctx context.Context, transactionId string) error, diags *diag.Diagnostics) {
+	err := client.ValidateChange(ctx, stage)
+	switch {
+	case err == nil:
+	case middleware.IsValidation(err):
+		diags.AddError(
+			"Invalid haproxy configuration",
+			"HAProxy rejected the planned configuration: "+err.Error(),
+		)
+	default:
+		diags.AddWarning(
+			"Could not validate haproxy configuration",
+			"The planned change could not be staged for validation, it is only validated on apply: "+err.Error(),
+		)
+	}
+}
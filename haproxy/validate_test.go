@@ -0,0 +1,69 @@
+package haproxy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccValidateOnPlan(t *testing.T) {
+	server, validateConfig := newTestServer(t, "validate_on_plan = true")
+
+	frontendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	backendName := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	server.PutObject("backends", "", map[string]interface{}{"name": backendName})
+
+	var requests int
+	frontendConfig := func(defaultBackend string) string {
+		return validateConfig + fmt.Sprintf(`
+		resource "haproxy-pf_frontend" "%s" {
+			name = "%s"
+			mode = "http"
+			default_backend = "%s"
+		}
+		`, frontendName, frontendName, defaultBackend)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// HAProxy rejects the planned frontend
+			{
+				Config:      frontendConfig("missing"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Invalid haproxy configuration"),
+			},
+			{
+				Config: frontendConfig(backendName),
+				Check:  resource.TestCheckResourceAttr(fmt.Sprintf("haproxy-pf_frontend.%s", frontendName), "default_backend", backendName),
+			},
+			// unchanged resources are not validated
+			{
+				PreConfig: func() { requests = len(server.Requests()) },
+				Config:    frontendConfig(backendName),
+				Check: func(*terraform.State) error {
+					for _, request := range server.Requests()[requests:] {
+						if strings.HasPrefix(request, "POST ") && strings.HasSuffix(request, "/transactions") {
+							return fmt.Errorf("unchanged frontend validated with %s", request)
+						}
+					}
+					return nil
+				},
+			},
+			// updates are validated too
+			{
+				Config:      frontendConfig("missing"),
+				ExpectError: regexp.MustCompile("Invalid haproxy configuration"),
+			},
+		},
+	})
+
+	if open := server.OpenTransactions(); open != 0 {
+		t.Errorf("%d validation transactions left open", open)
+	}
+}
@@ -0,0 +1,106 @@
+package haproxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var (
+	// identifierRegexp matches the characters HAProxy accepts in section names.
+	identifierRegexp = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+	// serverNameRegexp matches the characters accepted in server and bind
+	// names, which HAProxy does not restrict to those of section names. The
+	// "/" is escaped in IDs, see middleware.CreateChildId.
+	serverNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.:/-]+$`)
+	// hostnameRegexp matches RFC 1123 host names.
+	hostnameRegexp = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?\.)*[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?\.?$`)
+	// dnsNameRegexp matches DNS names, whose labels may also contain "_" as
+	// in the "_http._tcp.service.consul" SRV records.
+	dnsNameRegexp = regexp.MustCompile(`^([A-Za-z0-9_]([A-Za-z0-9_-]{0,61}[A-Za-z0-9_])?\.)*[A-Za-z0-9_]([A-Za-z0-9_-]{0,61}[A-Za-z0-9_])?\.?$`)
+	// numOrRangeRegexp matches server template counts ("3") and ranges ("1-3").
+	numOrRangeRegexp = regexp.MustCompile(`^[0-9]+(-[0-9]+)?$`)
+)
+
+// Values accepted by the Data Plane API.
+var (
+	proxyModes          = []string{"http", "tcp"}
+	httpConnectionModes = []string{"httpclose", "http-server-close", "http-keep-alive"}
+	balanceAlgorithms   = []string{"roundrobin", "static-rr", "leastconn", "first", "source", "uri", "url_param", "hdr", "random", "rdp-cookie", "hash"}
+	checkValues         = []string{"enabled", "disabled"}
+
+	// balanceMinHAProxyVersions are the HAProxy releases introducing the
+	// recent balance algorithms.
+	balanceMinHAProxyVersions = map[string]string{"hash": "2.6"}
+)
+
+// Characters of the names checked by identifierValidator and
+// serverNameValidator, for the attribute descriptions.
+const (
+	identifierCharacters = "letters, digits, '-', '_', '.' and ':'"
+	serverNameCharacters = "letters, digits, '-', '_', '.', ':' and '/'"
+)
+
+// identifierValidator checks HAProxy section names.
+func identifierValidator() validator.String {
+	return stringvalidator.RegexMatches(identifierRegexp, "must only contain "+identifierCharacters)
+}
+
+// serverNameValidator checks server and bind names.
+func serverNameValidator() validator.String {
+	return stringvalidator.RegexMatches(serverNameRegexp, "must only contain "+serverNameCharacters)
+}
+
+// portValidator checks TCP ports.
+func portValidator() validator.Int64 {
+	return int64validator.Between(1, 65535)
+}
+
+// dnsNameValidator checks the DNS names resolved by resolvers sections.
+func dnsNameValidator() validator.String {
+	return stringvalidator.All(
+		stringvalidator.LengthAtMost(253),
+		stringvalidator.RegexMatches(dnsNameRegexp, "must be a valid DNS name"),
+	)
+}
+
+// addressValidator checks IP addresses and host names, and the "*" wildcard
+// when allowWildcard is set.
+type addressValidator struct {
+	allowWildcard bool
+}
+
+func (v addressValidator) Description(_ context.Context) string {
+	if v.allowWildcard {
+		return "value must be an IP address, a hostname or *"
+	}
+	return "value must be an IP address or a hostname"
+}
+
+func (v addressValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v addressValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	address := req.ConfigValue.ValueString()
+	if net.ParseIP(address) != nil || (v.allowWildcard && address == "*") {
+		return
+	}
+	if len(address) <= 253 && hostnameRegexp.MatchString(address) {
+		return
+	}
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid Attribute Value",
+		fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), address),
+	)
+}
@@ -0,0 +1,177 @@
+package haproxy
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAddressValidator(t *testing.T) {
+	tests := []struct {
+		address       string
+		allowWildcard bool
+		wantErr       bool
+	}{
+		{address: "127.0.0.1"},
+		{address: "::1"},
+		{address: "backend.example.com"},
+		{address: "localhost"},
+		{address: "*", allowWildcard: true},
+		{address: "*", wantErr: true},
+		{address: "", wantErr: true},
+		{address: "10.0.0.1:80", wantErr: true},
+		{address: "bad_host.example.com", wantErr: true},
+		{address: "-bad.example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("address"), ConfigValue: types.StringValue(tt.address)}
+			var resp validator.StringResponse
+			addressValidator{allowWildcard: tt.allowWildcard}.ValidateString(context.Background(), req, &resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Errorf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestDnsNameValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "backend.example.com"},
+		{name: "backend.example.com."},
+		{name: "_http._tcp.service.consul"},
+		{name: "", wantErr: true},
+		{name: "bad host.example.com", wantErr: true},
+		{name: "-bad.example.com", wantErr: true},
+		{name: "bad..example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("fqdn"), ConfigValue: types.StringValue(tt.name)}
+			var resp validator.StringResponse
+			dnsNameValidator().ValidateString(context.Background(), req, &resp)
+			if resp.Diagnostics.HasError() != tt.wantErr {
+				t.Errorf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestAccResourceValidators(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "frontend mode",
+			config: `resource "haproxy-pf_frontend" "fe" {
+				name = "fe"
+				mode = "udp"
+			}`,
+			wantErr: `value must be one of`,
+		},
+		{
+			name: "frontend http_connection_mode",
+			config: `resource "haproxy-pf_frontend" "fe" {
+				name = "fe"
+				http_connection_mode = "keep-alive"
+			}`,
+			wantErr: `value must be one of`,
+		},
+		{
+			name: "backend balance",
+			config: `resource "haproxy-pf_backend" "be" {
+				name = "be"
+				balance = "round-robin"
+			}`,
+			wantErr: `value must be one of`,
+		},
+		{
+			name: "backend name",
+			config: `resource "haproxy-pf_backend" "be" {
+				name = "be web"
+				balance = "roundrobin"
+			}`,
+			wantErr: `must only contain letters`,
+		},
+		{
+			name: "backend name with slash",
+			config: `resource "haproxy-pf_backend" "be" {
+				name = "be/web"
+				balance = "roundrobin"
+			}`,
+			wantErr: `must only contain letters`,
+		},
+		{
+			name: "server check",
+			config: `resource "haproxy-pf_server" "srv" {
+				name = "srv"
+				address = "10.0.0.1"
+				port = 80
+				check = "true"
+				parent_name = "be"
+			}`,
+			wantErr: `value must be one of`,
+		},
+		{
+			name: "server port",
+			config: `resource "haproxy-pf_server" "srv" {
+				name = "srv"
+				address = "10.0.0.1"
+				port = 65536
+				check = "enabled"
+				parent_name = "be"
+			}`,
+			wantErr: `must be between 1 and 65535`,
+		},
+		{
+			name: "bind address",
+			config: `resource "haproxy-pf_bind" "bind" {
+				name = "bind"
+				address = "10.0.0.1:80"
+				port = 80
+				parent_name = "fe"
+			}`,
+			wantErr: `must be an IP address, a hostname or \*`,
+		},
+		{
+			name: "server template num_or_range",
+			config: `resource "haproxy-pf_server_template" "tpl" {
+				prefix = "srv"
+				fqdn = "backend.example.com"
+				num_or_range = "1..3"
+				port = 80
+				check = "enabled"
+				resolvers = "dns"
+				parent_name = "be"
+			}`,
+			wantErr: `must be a number or a range of numbers`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource.Test(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config:      providerConfig + tt.config,
+						PlanOnly:    true,
+						ExpectError: regexp.MustCompile(tt.wantErr),
+					},
+				},
+			})
+		})
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
 
+// version is set by goreleaser at build time.
+var version string = "dev"
+
 // Provider documentation generation.
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs generate --provider-name haproxy-pf
 
@@ -22,7 +25,7 @@ func main() {
 		Address: "registry.terraform.io/marcomezzaro/haproxy-pf",
 		Debug:   debug,
 	}
-	err := providerserver.Serve(context.Background(), haproxy.New, opts)
+	err := providerserver.Serve(context.Background(), haproxy.New(version), opts)
 
 	if err != nil {
 		log.Fatal(err.Error())